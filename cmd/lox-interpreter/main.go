@@ -1,73 +1,166 @@
 package main
 
 import (
+	"bytes"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/michalzarsm/lox-interpreter/dap"
+	"github.com/michalzarsm/lox-interpreter/lox"
+	"github.com/michalzarsm/lox-interpreter/lsp"
 )
 
-type Lox struct {
-	errors []Error
-}
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "repl" {
+		lox.NewRuntime().RunPrompt(os.Stdin)
+		return
+	}
 
-type ErrorType = string
+	command := os.Args[1]
 
-const (
-	SyntaxError       ErrorType = "SyntaxError"
-	RuntimeError      ErrorType = "RuntimeError"
-	ValueConvertError ErrorType = "ValueConvertError"
-)
+	if command == "lsp" {
+		if err := lsp.NewServer(os.Stdin, os.Stdout).Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "lsp: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-type Error struct {
-	errorType ErrorType
-	token     Token
-	message   string
-	exitCode  int
-}
+	if command == "dap" {
+		if err := dap.NewServer(os.Stdin, os.Stdout).Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "dap: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-func newLox() *Lox {
-	return &Lox{
-		errors: make([]Error, 0),
+	if command != "tokenize" && command != "parse" && command != "run" && command != "disassemble" && command != "compile" && command != "fmt" && command != "lint" {
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", command)
+		os.Exit(1)
 	}
-}
 
-func main() {
-	if len(os.Args) < 3 {
-		fmt.Fprintln(os.Stderr, "Usage: ./lox-interpreter.sh <command> <filename>")
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	backend := flags.String("backend", string(lox.BackendTree), "execution backend for run: tree or vm; compiled programs always run on vm")
+	format := flags.String("diagnostics", string(lox.DiagnosticsPretty), "error format: pretty, plain or json")
+	output := flags.String("o", "", "output file for compile (default: the source file with a .loxc extension)")
+	astFormat := flags.String("format", "sexpr", "parse: output format, sexpr or json")
+	check := flags.Bool("check", false, "fmt: only report whether the file is formatted, exiting with 1 if it isn't")
+	write := flags.Bool("w", false, "fmt: write the result back to the file instead of printing it")
+	flags.Parse(os.Args[2:])
+
+	// Flags stop at the first non-flag argument, so anything after the file
+	// name would be silently ignored.
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: ./lox-interpreter.sh [<command> [flags] <filename>]")
 		os.Exit(1)
 	}
 
-	command := os.Args[1]
+	if *backend != string(lox.BackendTree) && *backend != string(lox.BackendVM) {
+		fmt.Fprintf(os.Stderr, "Unknown backend: %s\n", *backend)
+		os.Exit(1)
+	}
 
-	if command != "tokenize" && command != "parse" && command != "run" {
-		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", command)
+	if *format != string(lox.DiagnosticsPretty) && *format != string(lox.DiagnosticsPlain) && *format != string(lox.DiagnosticsJSON) {
+		fmt.Fprintf(os.Stderr, "Unknown diagnostics format: %s\n", *format)
+		os.Exit(1)
+	}
+
+	if *astFormat != "sexpr" && *astFormat != "json" {
+		fmt.Fprintf(os.Stderr, "Unknown parse format: %s\n", *astFormat)
 		os.Exit(1)
 	}
 
-	filename := os.Args[2]
+	filename := flags.Arg(0)
 	fileContents, err := os.ReadFile(filename)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
 		os.Exit(1)
 	}
 
+	diagnostics = &lox.Diagnostics{
+		File:   filename,
+		Format: lox.DiagnosticFormat(*format),
+		Color:  isTerminal(os.Stderr),
+	}
+
+	// A compiled program carries the name of the file it was compiled from,
+	// and runtime errors point into that file rather than the .loxc.
+	var program *lox.Program
+	if lox.IsCompiledProgram(fileContents) {
+		if command != "run" && command != "disassemble" {
+			fmt.Fprintf(os.Stderr, "%s is a compiled program; %s needs Lox source\n", filename, command)
+			os.Exit(1)
+		}
+
+		if isFlagSet(flags, "backend") && *backend != string(lox.BackendVM) {
+			fmt.Fprintf(os.Stderr, "%s is a compiled program, which only runs on the vm backend\n", filename)
+			os.Exit(1)
+		}
+
+		program = &lox.Program{}
+		if err := program.UnmarshalBinary(fileContents); err != nil {
+			exit(err)
+		}
+
+		if program.SourceName != "" {
+			diagnostics.File = program.SourceName
+		}
+	} else {
+		diagnostics.Source = string(fileContents)
+	}
+
+	if command == "fmt" {
+		formatted, err := lox.Format(string(fileContents))
+		if err != nil {
+			exit(err)
+		}
+
+		switch {
+		case *check:
+			if formatted != string(fileContents) {
+				fmt.Fprintf(os.Stderr, "%s is not formatted\n", filename)
+				os.Exit(1)
+			}
+		case *write:
+			if err := os.WriteFile(filename, []byte(formatted), 0644); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing file: %v\n", err)
+				os.Exit(1)
+			}
+		default:
+			fmt.Print(formatted)
+		}
+		return
+	}
+
+	if command == "lint" {
+		warnings, err := lox.Lint(string(fileContents))
+		if err != nil {
+			exit(err)
+		}
+
+		if len(warnings) > 0 {
+			exit(warnings)
+		}
+		return
+	}
+
 	if len(fileContents) > 0 {
-		lox := newLox()
 		switch command {
 		case "tokenize":
 			{
-				scanner := newScanner(string(fileContents), lox)
-				scanner.scanTokens()
-
-				tokens := scanner.Tokens
+				tokens, err := lox.Tokenize(string(fileContents))
 
 				for _, token := range tokens {
-					if token.Type == STRING {
-						fmt.Printf("STRING \"%s\" %s\n", token.Lexeme, token.Literal)
-					} else if token.Type == NUMBER {
+					if token.Type == lox.STRING || token.Type == lox.INTERPOLATION {
+						fmt.Printf("%s %s %s\n", lox.TokenTypeName(string(token.Type)), token.Lexeme, escapeLiteral(token.Literal.(string)))
+					} else if token.Type == lox.NUMBER {
 						num, ok := token.Literal.(float64)
 						if !ok {
-							scanner.Lox.errors = append(scanner.Lox.errors, Error{errorType: ValueConvertError, token: token, message: "Not a float", exitCode: 65})
-							return
+							exit(lox.Errors{{Type: lox.ValueConvertError, Token: token, Message: "Not a float", ExitCode: 65}})
 						}
 
 						if num == float64(int(num)) {
@@ -76,45 +169,84 @@ func main() {
 							fmt.Printf("NUMBER %s %g\n", token.Lexeme, num)
 						}
 					} else {
-						fmt.Printf("%s %s null\n", getTokenTypeName(string(token.Type)), token.Lexeme)
+						fmt.Printf("%s %s null\n", lox.TokenTypeName(string(token.Type)), token.Lexeme)
 					}
 				}
 
-				if len(scanner.Lox.errors) > 0 {
-					lox.error()
+				if err != nil {
+					exit(err)
 				}
 			}
 		case "parse":
 			{
-				scanner := newScanner(string(fileContents), lox)
-				scanner.scanTokens()
-				if len(scanner.Lox.errors) > 0 {
-					lox.error()
+				statements, err := lox.Parse(string(fileContents))
+				if err != nil {
+					exit(err)
+				}
+
+				if *astFormat == "json" {
+					data, err := lox.MarshalAst(statements)
+					if err != nil {
+						exit(err)
+					}
+					fmt.Println(string(data))
+					return
+				}
+
+				printer := lox.NewAstPrinter()
+				for _, statement := range statements {
+					fmt.Println(printer.PrintStmt(statement))
+				}
+			}
+		case "disassemble":
+			{
+				if program != nil {
+					lox.Disassemble(os.Stdout, program.Script)
+					return
+				}
+
+				function, err := lox.Compile(string(fileContents))
+				if err != nil {
+					exit(err)
+				}
+				lox.Disassemble(os.Stdout, function)
+			}
+		case "compile":
+			{
+				function, err := lox.Compile(string(fileContents))
+				if err != nil {
+					exit(err)
+				}
+
+				program := &lox.Program{SourceName: filepath.Base(filename), Script: function}
+				data, err := program.MarshalBinary()
+				if err != nil {
+					exit(err)
 				}
-				tokens := scanner.Tokens
-				parser := newParser(tokens, lox)
-				statements := parser.parse()
-				if len(parser.Lox.errors) > 0 {
-					lox.error()
+
+				if *output == "" {
+					*output = strings.TrimSuffix(filename, filepath.Ext(filename)) + ".loxc"
+				}
+
+				if err := os.WriteFile(*output, data, 0644); err != nil {
+					fmt.Fprintf(os.Stderr, "Error writing file: %v\n", err)
+					os.Exit(1)
 				}
-				fmt.Printf("%v\n", statements)
 			}
 		case "run":
 			{
-				scanner := newScanner(string(fileContents), lox)
-				scanner.scanTokens()
-				if len(scanner.Lox.errors) > 0 {
-					lox.error()
+				runtime := lox.NewRuntime()
+				runtime.Backend = lox.Backend(*backend)
+
+				var err error
+				if program != nil {
+					err = runtime.RunProgram(program)
+				} else {
+					err = runtime.Run(bytes.NewReader(fileContents))
 				}
-				tokens := scanner.Tokens
-				parser := newParser(tokens, lox)
-				statements := parser.parse()
-				if len(parser.Lox.errors) > 0 {
-					lox.error()
+				if err != nil {
+					exit(err)
 				}
-				env := newEnvironment(nil)
-				interpreter := newInterpreter(env)
-				interpreter.interpret(statements)
 			}
 		}
 	} else {
@@ -122,11 +254,66 @@ func main() {
 	}
 }
 
-func (lox *Lox) error() {
-	for _, err := range lox.errors {
-		token := err.token
-		message := err.message
-		fmt.Fprintln(os.Stderr, fmt.Errorf("[line %d] Error: %s", token.Line, message))
+// diagnostics reports errors in the file given on the command line.
+var diagnostics = &lox.Diagnostics{Format: lox.DiagnosticsPlain}
+
+// exit reports the error and exits with the code of the first Lox error
+// it carries.
+func exit(err error) {
+	diagnostics.Report(os.Stderr, err)
+
+	var loxErrors lox.Errors
+	if errors.As(err, &loxErrors) && len(loxErrors) > 0 {
+		os.Exit(loxErrors[0].ExitCode)
+	}
+
+	os.Exit(1)
+}
+
+// escapeLiteral writes the value of a string token on one line, using the
+// escapes the scanner reads it back with.
+func escapeLiteral(value string) string {
+	var escaped strings.Builder
+	for _, r := range value {
+		switch {
+		case r == '\\':
+			escaped.WriteString(`\\`)
+		case r == '\n':
+			escaped.WriteString(`\n`)
+		case r == '\t':
+			escaped.WriteString(`\t`)
+		case r == '\r':
+			escaped.WriteString(`\r`)
+		case r < ' ' || r == 0x7f:
+			fmt.Fprintf(&escaped, `\u{%X}`, r)
+		default:
+			escaped.WriteRune(r)
+		}
 	}
-	os.Exit(lox.errors[0].exitCode)
+
+	return escaped.String()
+}
+
+// isFlagSet reports whether the flag was given on the command line, rather
+// than left at its default.
+func isFlagSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+
+	return set
+}
+
+// isTerminal reports whether file is a terminal rather than a pipe or a
+// regular file.
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
 }
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMain runs main instead of the tests when runLox starts the test
+// binary again as the command.
+func TestMain(m *testing.M) {
+	if os.Getenv("LOX_TEST_MAIN") == "1" {
+		main()
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+type result struct {
+	stdout string
+	stderr string
+	code   int
+}
+
+// runLox runs the command with args, feeding it stdin.
+func runLox(t *testing.T, stdin string, args ...string) result {
+	t.Helper()
+
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), "LOX_TEST_MAIN=1")
+	cmd.Stdin = strings.NewReader(stdin)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	code := 0
+	if err := cmd.Run(); err != nil {
+		var exit *exec.ExitError
+		if !errors.As(err, &exit) {
+			t.Fatalf("run %v: %v", args, err)
+		}
+		code = exit.ExitCode()
+	}
+
+	return result{stdout: stdout.String(), stderr: stderr.String(), code: code}
+}
+
+func writeFile(t *testing.T, name string, contents string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(contents)
+}
+
+func TestFormatFlags(t *testing.T) {
+	const unformatted = "var a=1;print a;\n"
+	const formatted = "var a = 1;\nprint a;\n"
+
+	path := writeFile(t, "main.lox", unformatted)
+
+	if got := runLox(t, "", "fmt", path); got.code != 0 || got.stdout != formatted {
+		t.Errorf("fmt exits with %d and prints %q, want 0 and %q", got.code, got.stdout, formatted)
+	}
+
+	got := runLox(t, "", "fmt", "--check", path)
+	if got.code != 1 || !strings.Contains(got.stderr, "is not formatted") {
+		t.Errorf("fmt --check on unformatted source exits with %d and reports %q", got.code, got.stderr)
+	}
+	if readFile(t, path) != unformatted {
+		t.Error("fmt --check changed the file")
+	}
+
+	if got := runLox(t, "", "fmt", "-w", path); got.code != 0 || got.stdout != "" {
+		t.Errorf("fmt -w exits with %d and prints %q, want 0 and nothing", got.code, got.stdout)
+	}
+	if contents := readFile(t, path); contents != formatted {
+		t.Errorf("fmt -w wrote %q, want %q", contents, formatted)
+	}
+
+	if got := runLox(t, "", "fmt", "--check", path); got.code != 0 {
+		t.Errorf("fmt --check on formatted source exits with %d: %s", got.code, got.stderr)
+	}
+
+	if got := runLox(t, "", "fmt", path, "--check"); got.code != 1 || !strings.Contains(got.stderr, "Usage") {
+		t.Errorf("a flag after the file name exits with %d and reports %q, want a usage error", got.code, got.stderr)
+	}
+
+	broken := writeFile(t, "broken.lox", "print ;\n")
+	if got := runLox(t, "", "fmt", "--check", broken); got.code != 65 {
+		t.Errorf("fmt --check on a syntax error exits with %d, want 65", got.code)
+	}
+}
+
+func TestTokenizeStrings(t *testing.T) {
+	path := writeFile(t, "strings.lox", "\"a\\nb\\t\\\\ \\u{1F600}\\u{7}\" \"sum ${1 + 2}!\" `raw\\n`\n")
+
+	want := strings.Join([]string{
+		`STRING "a\nb\t\\ \u{1F600}\u{7}" a\nb\t\\ 😀\u{7}`,
+		`INTERPOLATION "sum ${ sum `,
+		`NUMBER 1 1.0`,
+		`PLUS + null`,
+		`NUMBER 2 2.0`,
+		`STRING }!" !`,
+		"STRING `raw\\n` raw\\\\n",
+		`EOF  null`,
+	}, "\n") + "\n"
+
+	got := runLox(t, "", "tokenize", path)
+	if got.code != 0 {
+		t.Fatalf("tokenize exits with %d: %s", got.code, got.stderr)
+	}
+	if got.stdout != want {
+		t.Errorf("tokens are\n%s\nwant\n%s", got.stdout, want)
+	}
+}
+
+func TestLintExitCodes(t *testing.T) {
+	clean := writeFile(t, "clean.lox", "print 1;\n")
+	if got := runLox(t, "", "lint", clean); got.code != 0 || got.stderr != "" {
+		t.Errorf("lint on clean source exits with %d and reports %q", got.code, got.stderr)
+	}
+
+	warned := writeFile(t, "warned.lox", "var unused = 1;\nvar a = 1 + \"a\"; // lint-disable-line\nprint a;\n")
+	got := runLox(t, "", "lint", "--diagnostics=plain", warned)
+	want := "[line 1] Warning at 'unused': Variable 'unused' is never used. [unused-variable]\n"
+	if got.code != 1 || got.stderr != want {
+		t.Errorf("lint exits with %d and reports %q, want 1 and %q", got.code, got.stderr, want)
+	}
+
+	broken := writeFile(t, "broken.lox", "print ;\n")
+	if got := runLox(t, "", "lint", broken); got.code != 65 {
+		t.Errorf("lint on a syntax error exits with %d, want 65", got.code)
+	}
+}
+
+func TestRunCompiledProgram(t *testing.T) {
+	source := writeFile(t, "div.lox", "print 1;\nprint 1 + nil;\n")
+	compiled := filepath.Join(filepath.Dir(source), "div.loxc")
+	if got := runLox(t, "", "compile", "-o", compiled, source); got.code != 0 {
+		t.Fatalf("compile exits with %d: %s", got.code, got.stderr)
+	}
+
+	for _, args := range [][]string{{"run"}, {"run", "--backend=vm"}} {
+		got := runLox(t, "", append(args, "--diagnostics=plain", compiled)...)
+		if got.code != 70 || got.stdout != "1\n" || got.stderr != "Operands must be two numbers or two strings.\n[line 2]\n" {
+			t.Errorf("%v exits with %d, prints %q and reports %q", args, got.code, got.stdout, got.stderr)
+		}
+	}
+
+	got := runLox(t, "", "run", "--backend=tree", compiled)
+	if got.code != 1 || !strings.Contains(got.stderr, "only runs on the vm backend") || got.stdout != "" {
+		t.Errorf("run --backend=tree on a compiled program exits with %d and reports %q", got.code, got.stderr)
+	}
+
+	got = runLox(t, "", "disassemble", compiled)
+	if got.code != 0 || !strings.Contains(got.stdout, "== <script> ==") {
+		t.Errorf("disassemble on a compiled program exits with %d and prints %q", got.code, got.stdout)
+	}
+}
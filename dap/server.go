@@ -0,0 +1,263 @@
+// Package dap implements a Debug Adapter Protocol server for Lox. It
+// debugs one program on the tree-walking interpreter and speaks the
+// protocol over any reader and writer, so it can be driven over stdio by
+// an editor or by a scripted client.
+package dap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/michalzarsm/lox-interpreter/internal/framing"
+	"github.com/michalzarsm/lox-interpreter/lox"
+)
+
+// Server answers the requests of a single debugging session.
+type Server struct {
+	reader *bufio.Reader
+
+	writeMu sync.Mutex
+	writer  io.Writer
+	seq     int
+
+	session    *session
+	launch     *LaunchArguments
+	source     []byte
+	configured bool
+	started    bool
+}
+
+func NewServer(in io.Reader, out io.Writer) *Server {
+	server := &Server{
+		reader: bufio.NewReader(in),
+		writer: out,
+	}
+	server.session = newSession(server)
+
+	return server
+}
+
+// Run serves requests until the client disconnects or closes the input.
+func (s *Server) Run() error {
+	for {
+		body, err := framing.Read(s.reader)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			return err
+		}
+
+		result, err := s.handle(req)
+		if err != nil {
+			s.send(response{Type: "response", RequestSeq: req.Seq, Success: false, Command: req.Command, Message: err.Error()})
+		} else {
+			s.send(response{Type: "response", RequestSeq: req.Seq, Success: true, Command: req.Command, Body: result})
+		}
+
+		switch req.Command {
+		case "initialize":
+			s.event("initialized", nil)
+		case "launch", "configurationDone":
+			if err == nil {
+				s.start()
+			}
+		case "disconnect", "terminate":
+			return nil
+		}
+	}
+}
+
+func (s *Server) handle(req request) (any, error) {
+	switch req.Command {
+	case "initialize":
+		return Capabilities{SupportsConfigurationDoneRequest: true, SupportsTerminateRequest: true}, nil
+	case "launch":
+		var arguments LaunchArguments
+		if err := json.Unmarshal(req.Arguments, &arguments); err != nil {
+			return nil, err
+		}
+
+		source, err := os.ReadFile(arguments.Program)
+		if err != nil {
+			return nil, err
+		}
+
+		if lox.IsCompiledProgram(source) {
+			return nil, fmt.Errorf("%s is a compiled program; launch its Lox source to debug it", arguments.Program)
+		}
+
+		s.launch = &arguments
+		s.source = source
+		return nil, nil
+	case "setBreakpoints":
+		var arguments SetBreakpointsArguments
+		if err := json.Unmarshal(req.Arguments, &arguments); err != nil {
+			return nil, err
+		}
+
+		return map[string]any{"breakpoints": s.setBreakpoints(arguments)}, nil
+	case "configurationDone":
+		s.configured = true
+		return nil, nil
+	case "threads":
+		return map[string]any{"threads": []Thread{{ID: 1, Name: "main"}}}, nil
+	case "stackTrace":
+		frames := s.session.stackFrames(s.sourceReference())
+		return map[string]any{"stackFrames": frames, "totalFrames": len(frames)}, nil
+	case "scopes":
+		var arguments ScopesArguments
+		if err := json.Unmarshal(req.Arguments, &arguments); err != nil {
+			return nil, err
+		}
+
+		return map[string]any{"scopes": s.session.scopes(arguments.FrameID)}, nil
+	case "variables":
+		var arguments VariablesArguments
+		if err := json.Unmarshal(req.Arguments, &arguments); err != nil {
+			return nil, err
+		}
+
+		return map[string]any{"variables": s.session.variables(arguments.VariablesReference)}, nil
+	case "continue":
+		return map[string]any{"allThreadsContinued": true}, s.resume(stepNone)
+	case "next":
+		return nil, s.resume(stepOver)
+	case "stepIn":
+		return nil, s.resume(stepIn)
+	case "stepOut":
+		return nil, s.resume(stepOut)
+	case "pause":
+		s.session.requestPause()
+		return nil, nil
+	case "disconnect", "terminate":
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("unsupported request '%s'", req.Command)
+}
+
+func (s *Server) resume(mode stepMode) error {
+	if !s.session.continueWith(mode) {
+		return errors.New("the program is not stopped")
+	}
+
+	return nil
+}
+
+// setBreakpoints replaces the breakpoints of the launched program. Only
+// lines where a statement starts can hold one.
+func (s *Server) setBreakpoints(arguments SetBreakpointsArguments) []Breakpoint {
+	statementLines := make(map[int]bool)
+	if s.launch != nil {
+		lox.WalkStatements(lox.Analyze(string(s.source)).Statements, func(stmt lox.Stmt) {
+			statementLines[stmt.Span().Start.Line] = true
+		})
+	}
+
+	breakpoints := make([]Breakpoint, 0, len(arguments.Breakpoints))
+	lines := make([]int, 0, len(arguments.Breakpoints))
+	for _, requested := range arguments.Breakpoints {
+		breakpoint := Breakpoint{Verified: statementLines[requested.Line], Line: requested.Line}
+		if breakpoint.Verified {
+			lines = append(lines, requested.Line)
+		} else {
+			breakpoint.Message = "No statement starts on this line."
+		}
+
+		breakpoints = append(breakpoints, breakpoint)
+	}
+
+	s.session.setBreakpoints(lines)
+	return breakpoints
+}
+
+func (s *Server) sourceReference() Source {
+	if s.launch == nil {
+		return Source{}
+	}
+
+	return Source{Name: filepath.Base(s.launch.Program), Path: s.launch.Program}
+}
+
+// start runs the program once it has been launched and the client has
+// finished setting breakpoints.
+func (s *Server) start() {
+	if s.launch == nil || !s.configured || s.started {
+		return
+	}
+	s.started = true
+
+	runtime := lox.NewRuntime()
+	runtime.Stdout = &outputWriter{server: s, category: "stdout"}
+	if !s.launch.NoDebug {
+		runtime.Debugger = s.session
+		if s.launch.StopOnEntry {
+			s.session.mode = stepEntry
+		}
+	}
+
+	diagnostics := &lox.Diagnostics{File: s.launch.Program, Source: string(s.source), Format: lox.DiagnosticsPlain}
+
+	go func() {
+		exitCode := 0
+		if err := runtime.Run(bytes.NewReader(s.source)); err != nil {
+			var output bytes.Buffer
+			diagnostics.Report(&output, err)
+			s.event("output", OutputEventBody{Category: "stderr", Output: output.String()})
+
+			exitCode = 1
+			var loxErrors lox.Errors
+			if errors.As(err, &loxErrors) && len(loxErrors) > 0 {
+				exitCode = loxErrors[0].ExitCode
+			}
+		}
+
+		s.event("exited", ExitedEventBody{ExitCode: exitCode})
+		s.event("terminated", nil)
+	}()
+}
+
+func (s *Server) event(name string, body any) {
+	s.send(event{Type: "event", Event: name, Body: body})
+}
+
+// send writes a response or event. It is called from both the server and
+// the program goroutine.
+func (s *Server) send(message any) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.seq += 1
+	switch message := message.(type) {
+	case response:
+		message.Seq = s.seq
+		framing.Write(s.writer, message)
+	case event:
+		message.Seq = s.seq
+		framing.Write(s.writer, message)
+	}
+}
+
+// outputWriter turns what the program prints into output events.
+type outputWriter struct {
+	server   *Server
+	category string
+}
+
+func (w *outputWriter) Write(p []byte) (int, error) {
+	w.server.event("output", OutputEventBody{Category: w.category, Output: string(p)})
+	return len(p), nil
+}
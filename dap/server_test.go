@@ -0,0 +1,362 @@
+package dap_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/michalzarsm/lox-interpreter/dap"
+	"github.com/michalzarsm/lox-interpreter/internal/framing"
+	"github.com/michalzarsm/lox-interpreter/lox"
+)
+
+const program = `fun add(a, b) {
+  var sum = a + b;
+  return sum;
+}
+
+var x = 1;
+var y = add(x, 2);
+print y;
+print "done";
+`
+
+// client drives a Server over pipes the way an editor would. Events can
+// arrive while it waits for a response, since the program runs on its own
+// goroutine, so they are kept until asked for.
+type client struct {
+	t      *testing.T
+	reader *bufio.Reader
+	writer io.WriteCloser
+	seq    int
+	done   chan error
+	events []message
+}
+
+type message struct {
+	Seq        int             `json:"seq"`
+	Type       string          `json:"type"`
+	RequestSeq int             `json:"request_seq"`
+	Success    bool            `json:"success"`
+	Command    string          `json:"command"`
+	Message    string          `json:"message"`
+	Event      string          `json:"event"`
+	Body       json.RawMessage `json:"body"`
+}
+
+func newClient(t *testing.T) *client {
+	t.Helper()
+
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	c := &client{
+		t:      t,
+		reader: bufio.NewReader(clientIn),
+		writer: clientOut,
+		done:   make(chan error, 1),
+	}
+
+	go func() {
+		c.done <- dap.NewServer(serverIn, serverOut).Run()
+		serverOut.Close()
+	}()
+
+	t.Cleanup(func() {
+		clientOut.Close()
+		if err := <-c.done; err != nil {
+			t.Errorf("server stopped with %v", err)
+		}
+	})
+
+	return c
+}
+
+func (c *client) read() message {
+	c.t.Helper()
+
+	body, err := framing.Read(c.reader)
+	if err != nil {
+		c.t.Fatalf("read: %v", err)
+	}
+
+	var msg message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		c.t.Fatalf("decode %s: %v", body, err)
+	}
+
+	return msg
+}
+
+// request sends a request and returns its response, which the caller
+// checks for success.
+func (c *client) request(command string, arguments any) message {
+	c.t.Helper()
+
+	c.seq += 1
+	seq := c.seq
+	if err := framing.Write(c.writer, map[string]any{"seq": seq, "type": "request", "command": command, "arguments": arguments}); err != nil {
+		c.t.Fatalf("write: %v", err)
+	}
+
+	for {
+		msg := c.read()
+		if msg.Type == "event" {
+			c.events = append(c.events, msg)
+			continue
+		}
+
+		if msg.RequestSeq != seq || msg.Command != command {
+			c.t.Fatalf("got the response to %s %d while waiting for %s %d", msg.Command, msg.RequestSeq, command, seq)
+		}
+		return msg
+	}
+}
+
+// call sends a request that must succeed and decodes its body into body.
+func (c *client) call(command string, arguments any, body any) {
+	c.t.Helper()
+
+	response := c.request(command, arguments)
+	if !response.Success {
+		c.t.Fatalf("%s failed: %s", command, response.Message)
+	}
+
+	if body != nil {
+		if err := json.Unmarshal(response.Body, body); err != nil {
+			c.t.Fatalf("%s: decode %s: %v", command, response.Body, err)
+		}
+	}
+}
+
+// next returns the next event, queued or not.
+func (c *client) next() message {
+	c.t.Helper()
+
+	if len(c.events) > 0 {
+		msg := c.events[0]
+		c.events = c.events[1:]
+		return msg
+	}
+
+	return c.read()
+}
+
+// event waits for the next event, which must be called name, and decodes
+// its body into body.
+func (c *client) event(name string, body any) {
+	c.t.Helper()
+
+	msg := c.next()
+	if msg.Type != "event" || msg.Event != name {
+		c.t.Fatalf("got %s %s%s while waiting for the %s event", msg.Type, msg.Event, msg.Command, name)
+	}
+
+	if body != nil {
+		if err := json.Unmarshal(msg.Body, body); err != nil {
+			c.t.Fatalf("%s: decode %s: %v", name, msg.Body, err)
+		}
+	}
+}
+
+// stopped waits for the program to stop and returns the reason and the
+// stack, innermost frame first.
+func (c *client) stopped() (string, []dap.StackFrame) {
+	c.t.Helper()
+
+	var stop dap.StoppedEventBody
+	c.event("stopped", &stop)
+
+	var trace struct {
+		StackFrames []dap.StackFrame `json:"stackFrames"`
+	}
+	c.call("stackTrace", map[string]any{"threadId": 1}, &trace)
+
+	return stop.Reason, trace.StackFrames
+}
+
+// variables lists the variables of the named scope of a frame as
+// "name = value".
+func (c *client) variables(frameID int, scope string) []string {
+	c.t.Helper()
+
+	var scopes struct {
+		Scopes []dap.Scope `json:"scopes"`
+	}
+	c.call("scopes", dap.ScopesArguments{FrameID: frameID}, &scopes)
+
+	for _, s := range scopes.Scopes {
+		if s.Name != scope {
+			continue
+		}
+
+		var variables struct {
+			Variables []dap.Variable `json:"variables"`
+		}
+		c.call("variables", dap.VariablesArguments{VariablesReference: s.VariablesReference}, &variables)
+
+		result := make([]string, 0, len(variables.Variables))
+		for _, variable := range variables.Variables {
+			result = append(result, variable.Name+" = "+variable.Value)
+		}
+		return result
+	}
+
+	c.t.Fatalf("frame %d has no %q scope among %+v", frameID, scope, scopes.Scopes)
+	return nil
+}
+
+func writeProgram(t *testing.T, name string, contents []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, contents, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestSession(t *testing.T) {
+	path := writeProgram(t, "add.lox", []byte(program))
+	c := newClient(t)
+
+	var capabilities dap.Capabilities
+	c.call("initialize", map[string]any{"adapterID": "lox"}, &capabilities)
+	if !capabilities.SupportsConfigurationDoneRequest {
+		t.Error("the server doesn't ask for configurationDone")
+	}
+	c.event("initialized", nil)
+
+	c.call("launch", dap.LaunchArguments{Program: path}, nil)
+
+	var set struct {
+		Breakpoints []dap.Breakpoint `json:"breakpoints"`
+	}
+	c.call("setBreakpoints", dap.SetBreakpointsArguments{
+		Source:      dap.Source{Path: path},
+		Breakpoints: []dap.SourceBreakpoint{{Line: 7}, {Line: 5}},
+	}, &set)
+
+	wantBreakpoints := []dap.Breakpoint{
+		{Verified: true, Line: 7},
+		{Verified: false, Line: 5, Message: "No statement starts on this line."},
+	}
+	if !reflect.DeepEqual(set.Breakpoints, wantBreakpoints) {
+		t.Errorf("breakpoints are %+v, want %+v", set.Breakpoints, wantBreakpoints)
+	}
+
+	c.call("configurationDone", nil, nil)
+
+	// The breakpoint on the call to add.
+	reason, frames := c.stopped()
+	if reason != "breakpoint" || len(frames) != 1 || frames[0].Line != 7 || frames[0].Source.Path != path {
+		t.Fatalf("stopped for %q at %+v, want the breakpoint on line 7", reason, frames)
+	}
+	if globals := c.variables(frames[0].ID, "Globals"); !contains(globals, "x = 1") || !contains(globals, "add = <fn add>") {
+		t.Errorf("globals are %q", globals)
+	}
+
+	// Into add, whose locals are its parameters.
+	c.call("stepIn", map[string]any{"threadId": 1}, nil)
+	reason, frames = c.stopped()
+	if reason != "step" || len(frames) != 2 || frames[0].Name != "add" || frames[0].Line != 2 {
+		t.Fatalf("stepped in for %q to %+v, want line 2 of add", reason, frames)
+	}
+	if locals := c.variables(frames[0].ID, "Locals"); !reflect.DeepEqual(locals, []string{"a = 1", "b = 2"}) {
+		t.Errorf("locals of add are %q", locals)
+	}
+
+	// Over the declaration of sum.
+	c.call("next", map[string]any{"threadId": 1}, nil)
+	reason, frames = c.stopped()
+	if reason != "step" || len(frames) != 2 || frames[0].Line != 3 {
+		t.Fatalf("stepped over for %q to %+v, want line 3 of add", reason, frames)
+	}
+	if locals := c.variables(frames[0].ID, "Locals"); !contains(locals, "sum = 3") {
+		t.Errorf("locals of add are %q, want sum = 3 among them", locals)
+	}
+
+	// Out of add, to the statement after the call.
+	c.call("stepOut", map[string]any{"threadId": 1}, nil)
+	reason, frames = c.stopped()
+	if reason != "step" || len(frames) != 1 || frames[0].Line != 8 {
+		t.Fatalf("stepped out for %q to %+v, want line 8", reason, frames)
+	}
+
+	c.call("continue", map[string]any{"threadId": 1}, nil)
+
+	var output strings.Builder
+	for {
+		var body struct {
+			dap.OutputEventBody
+			dap.ExitedEventBody
+		}
+
+		msg := c.next()
+		if msg.Event != "output" {
+			if msg.Event != "exited" {
+				t.Fatalf("got the %s event while waiting for the program to exit", msg.Event)
+			}
+			if err := json.Unmarshal(msg.Body, &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.ExitCode != 0 {
+				t.Errorf("the program exited with %d", body.ExitCode)
+			}
+			break
+		}
+
+		if err := json.Unmarshal(msg.Body, &body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Category == "stdout" {
+			output.WriteString(body.Output)
+		}
+	}
+	c.event("terminated", nil)
+
+	if output.String() != "3\ndone\n" {
+		t.Errorf("the program printed %q", output.String())
+	}
+
+	c.call("disconnect", nil, nil)
+}
+
+func TestLaunchRejectsCompiledPrograms(t *testing.T) {
+	script, err := lox.Compile("print 1;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := (&lox.Program{SourceName: "one.lox", Script: script}).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := writeProgram(t, "one.loxc", data)
+
+	c := newClient(t)
+	c.call("initialize", map[string]any{"adapterID": "lox"}, nil)
+	c.event("initialized", nil)
+
+	response := c.request("launch", dap.LaunchArguments{Program: path})
+	if response.Success || !strings.Contains(response.Message, "compiled program") {
+		t.Errorf("launching a compiled program gives success %t and %q", response.Success, response.Message)
+	}
+
+	c.call("disconnect", nil, nil)
+}
+
+func contains(list []string, item string) bool {
+	for _, element := range list {
+		if element == item {
+			return true
+		}
+	}
+
+	return false
+}
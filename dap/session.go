@@ -0,0 +1,215 @@
+package dap
+
+import (
+	"sync"
+
+	"github.com/michalzarsm/lox-interpreter/lox"
+)
+
+type stepMode int
+
+const (
+	stepNone stepMode = iota
+	stepEntry
+	stepIn
+	stepOver
+	stepOut
+)
+
+// session is the lox.Debugger for a launched program. The program runs on
+// its own goroutine and blocks in Step while it is stopped; the server
+// goroutine inspects it and resumes it through the session.
+type session struct {
+	server *Server
+
+	mu          sync.Mutex
+	breakpoints map[int]bool
+	mode        stepMode
+	stepDepth   int
+	stepLine    int
+	pause       bool
+	lastLine    int
+	lastDepth   int
+
+	stopped    bool
+	frames     []*lox.StackFrame
+	references [][]lox.Variable
+	resume     chan struct{}
+}
+
+func newSession(server *Server) *session {
+	return &session{
+		server:      server,
+		breakpoints: make(map[int]bool),
+		resume:      make(chan struct{}),
+	}
+}
+
+// Step stops the program when it reaches a breakpoint, finishes a step or
+// has been asked to pause, and waits for the client to resume it.
+func (s *session) Step(stmt lox.Stmt, frames []*lox.StackFrame) {
+	line := stmt.Span().Start.Line
+	depth := len(frames)
+
+	s.mu.Lock()
+	reason := s.stopReason(line, depth)
+	s.lastLine = line
+	s.lastDepth = depth
+
+	if reason == "" {
+		s.mu.Unlock()
+		return
+	}
+
+	s.stopped = true
+	s.frames = append([]*lox.StackFrame(nil), frames...)
+	s.references = nil
+	s.mode = stepNone
+	s.pause = false
+	s.mu.Unlock()
+
+	s.server.event("stopped", StoppedEventBody{Reason: reason, ThreadID: 1, AllThreadsStopped: true})
+	<-s.resume
+}
+
+// stopReason decides whether to stop before a statement on line at the
+// given call depth. Several statements can start on one line, so a
+// breakpoint or a step only stops on the first of them.
+func (s *session) stopReason(line int, depth int) string {
+	if s.pause {
+		return "pause"
+	}
+
+	switch s.mode {
+	case stepEntry:
+		return "entry"
+	case stepIn:
+		if line != s.stepLine || depth != s.stepDepth {
+			return "step"
+		}
+	case stepOver:
+		if depth < s.stepDepth || (depth == s.stepDepth && line != s.stepLine) {
+			return "step"
+		}
+	case stepOut:
+		if depth < s.stepDepth {
+			return "step"
+		}
+	}
+
+	if s.breakpoints[line] && (line != s.lastLine || depth != s.lastDepth) {
+		return "breakpoint"
+	}
+
+	return ""
+}
+
+func (s *session) setBreakpoints(lines []int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.breakpoints = make(map[int]bool)
+	for _, line := range lines {
+		s.breakpoints[line] = true
+	}
+}
+
+// continueWith resumes a stopped program in the given mode. It reports
+// false if the program isn't stopped.
+func (s *session) continueWith(mode stepMode) bool {
+	s.mu.Lock()
+	if !s.stopped {
+		s.mu.Unlock()
+		return false
+	}
+
+	s.mode = mode
+	if len(s.frames) > 0 {
+		s.stepDepth = len(s.frames)
+		s.stepLine = s.frames[len(s.frames)-1].Statement.Span().Start.Line
+	}
+	s.stopped = false
+	s.frames = nil
+	s.references = nil
+	s.mu.Unlock()
+
+	s.resume <- struct{}{}
+	return true
+}
+
+func (s *session) requestPause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.stopped {
+		s.pause = true
+	}
+}
+
+// stackFrames returns the frames of the stopped program, innermost first.
+// Frame IDs index the frames outermost first.
+func (s *session) stackFrames(source Source) []StackFrame {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]StackFrame, 0, len(s.frames))
+	for i := len(s.frames) - 1; i >= 0; i-- {
+		frame := s.frames[i]
+		start := frame.Statement.Span().Start
+		result = append(result, StackFrame{ID: i, Name: frame.Name, Source: source, Line: start.Line, Column: start.Column})
+	}
+
+	return result
+}
+
+func (s *session) scopes(frameID int) []Scope {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]Scope, 0)
+	if frameID < 0 || frameID >= len(s.frames) {
+		return result
+	}
+
+	for _, scope := range s.frames[frameID].Scopes() {
+		result = append(result, Scope{Name: scope.Name, VariablesReference: s.reference(scope.Variables)})
+	}
+
+	return result
+}
+
+func (s *session) variables(reference int) []Variable {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]Variable, 0)
+	if reference < 1 || reference > len(s.references) {
+		return result
+	}
+
+	for _, variable := range s.references[reference-1] {
+		child := 0
+		if fields := lox.Fields(variable.Value); fields != nil {
+			child = s.reference(fields)
+		}
+
+		result = append(result, Variable{Name: variable.Name, Value: formatValue(variable.Value), VariablesReference: child})
+	}
+
+	return result
+}
+
+// reference hands out a variables reference for the variables. References
+// are only valid while the program stays stopped.
+func (s *session) reference(variables []lox.Variable) int {
+	s.references = append(s.references, variables)
+	return len(s.references)
+}
+
+func formatValue(value lox.Value) string {
+	if text, ok := value.(string); ok {
+		return "\"" + text + "\""
+	}
+
+	return lox.Stringify(value)
+}
@@ -0,0 +1,125 @@
+package lox
+
+import "io"
+
+// Analysis is what can be learned about a program without running it: its
+// tokens, comments and statements, every static error, and the symbols it
+// declares.
+// Unlike Parse, Analyze keeps going after errors so editors have something
+// to work with while the source is being typed.
+type Analysis struct {
+	Tokens     []Token
+	Comments   []Token
+	Statements []Stmt
+	Errors     []Error
+	Symbols    []*Symbol
+}
+
+// Analyze scans, parses and resolves the source.
+func Analyze(source string) *Analysis {
+	lox := newLox()
+
+	scanner := newScanner(source, lox)
+	scanner.scanTokens()
+
+	parser := newParser(scanner.Tokens, lox)
+	statements := parser.parse()
+
+	resolver := newResolver(newInterpreter(newEnvironment(nil), io.Discard), lox)
+	resolver.symbols = newSymbolTable()
+	resolver.resolve(statements)
+	resolver.symbols.finish()
+
+	return &Analysis{
+		Tokens:     scanner.Tokens,
+		Comments:   scanner.Comments,
+		Statements: statements,
+		Errors:     lox.errors,
+		Symbols:    resolver.symbols.symbols,
+	}
+}
+
+// SymbolAt returns the symbol whose declaration or one of whose uses covers
+// the byte offset, and the token found there.
+func (a *Analysis) SymbolAt(offset int) (*Symbol, Token, bool) {
+	for _, symbol := range a.Symbols {
+		if covers(symbol.Name.Span, offset) {
+			return symbol, symbol.Name, true
+		}
+
+		for _, reference := range symbol.References {
+			if covers(reference.Span, offset) {
+				return symbol, reference, true
+			}
+		}
+	}
+
+	return nil, Token{}, false
+}
+
+// VisibleAt returns the symbols that can be referred to by name at the
+// byte offset: those declared at the top level and those declared earlier
+// inside the functions that enclose it. Methods are left out since they
+// are only reached through an instance.
+func (a *Analysis) VisibleAt(offset int) []*Symbol {
+	visible := make([]*Symbol, 0)
+
+	for _, symbol := range a.Symbols {
+		if symbol.Kind == SymbolMethod {
+			continue
+		}
+
+		if symbol.Parent == nil {
+			visible = append(visible, symbol)
+			continue
+		}
+
+		if symbol.Name.Span.End.Offset <= offset && covers(symbol.Parent.Declaration.Span(), offset) {
+			visible = append(visible, symbol)
+		}
+	}
+
+	return visible
+}
+
+// covers reports whether offset is inside span or right at its end, where
+// the cursor sits after typing a name.
+func covers(span Span, offset int) bool {
+	return span.Start.Line > 0 && span.Start.Offset <= offset && offset <= span.End.Offset
+}
+
+// WalkStatements calls visit for every statement in source order,
+// including those nested in blocks, control flow and function and method
+// bodies.
+func WalkStatements(statements []Stmt, visit func(stmt Stmt)) {
+	for _, stmt := range statements {
+		walkStatement(stmt, visit)
+	}
+}
+
+func walkStatement(stmt Stmt, visit func(stmt Stmt)) {
+	if stmt == nil {
+		return
+	}
+
+	visit(stmt)
+
+	switch stmt := stmt.(type) {
+	case *Block:
+		WalkStatements(stmt.Statements, visit)
+	case *Class:
+		for _, method := range stmt.Methods {
+			walkStatement(method, visit)
+		}
+	case *For:
+		walkStatement(stmt.Initializer, visit)
+		walkStatement(stmt.Body, visit)
+	case *Function:
+		WalkStatements(stmt.Body, visit)
+	case *If:
+		walkStatement(stmt.ThenBranch, visit)
+		walkStatement(stmt.ElseBranch, visit)
+	case *While:
+		walkStatement(stmt.Body, visit)
+	}
+}
@@ -0,0 +1,303 @@
+package lox
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// MarshalAst encodes statements as an indented JSON array. Every node is
+// an object that starts with its kind and span, followed by its children
+// and, for literals, the value itself.
+func MarshalAst(statements []Stmt) ([]byte, error) {
+	return json.MarshalIndent(astJSON{}.statements(statements), "", "  ")
+}
+
+// astNode is a JSON object that keeps its fields in the order they were
+// added, so that every node reads kind and span first.
+type astNode []astField
+
+type astField struct {
+	name  string
+	value any
+}
+
+func (n astNode) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteString("{")
+	for i, field := range n {
+		if i > 0 {
+			buffer.WriteString(",")
+		}
+
+		name, err := json.Marshal(field.name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(field.value)
+		if err != nil {
+			return nil, err
+		}
+
+		buffer.Write(name)
+		buffer.WriteString(":")
+		buffer.Write(value)
+	}
+	buffer.WriteString("}")
+
+	return buffer.Bytes(), nil
+}
+
+// astJSON turns the AST into astNodes. Names and operators are given as
+// their lexemes, and missing parts, such as an absent else branch, as null.
+type astJSON struct{}
+
+func (a astJSON) node(kind string, span Span, fields ...astField) astNode {
+	return append(astNode{{"kind", kind}, {"span", span}}, fields...)
+}
+
+func (a astJSON) expr(expr Expr) any {
+	if expr == nil {
+		return nil
+	}
+
+	return expr.Accept(a)
+}
+
+func (a astJSON) stmt(stmt Stmt) any {
+	if stmt == nil {
+		return nil
+	}
+
+	return stmt.Accept(a)
+}
+
+// label gives the label of a loop, or of the loop a break or continue
+// names, as null when there is none.
+func (a astJSON) label(label Token) any {
+	if label.Lexeme == "" {
+		return nil
+	}
+
+	return label.Lexeme
+}
+
+func (a astJSON) exprs(exprs []Expr) []any {
+	nodes := make([]any, 0, len(exprs))
+	for _, expr := range exprs {
+		nodes = append(nodes, a.expr(expr))
+	}
+
+	return nodes
+}
+
+func (a astJSON) statements(statements []Stmt) []any {
+	nodes := make([]any, 0, len(statements))
+	for _, stmt := range statements {
+		nodes = append(nodes, a.stmt(stmt))
+	}
+
+	return nodes
+}
+
+func (a astJSON) VisitBlockStmt(stmt *Block) any {
+	return a.node("Block", stmt.Span(), astField{"statements", a.statements(stmt.Statements)})
+}
+
+func (a astJSON) VisitBreakStmt(stmt *Break) any {
+	return a.node("Break", stmt.Span(), astField{"label", a.label(stmt.Label)})
+}
+
+func (a astJSON) VisitContinueStmt(stmt *Continue) any {
+	return a.node("Continue", stmt.Span(), astField{"label", a.label(stmt.Label)})
+}
+
+func (a astJSON) VisitClassStmt(stmt *Class) any {
+	methods := make([]any, 0, len(stmt.Methods))
+	for _, method := range stmt.Methods {
+		methods = append(methods, a.VisitFunctionStmt(method))
+	}
+
+	return a.node("Class", stmt.Span(),
+		astField{"name", stmt.Name.Lexeme},
+		astField{"superclass", a.expr(stmt.Superclass)},
+		astField{"methods", methods},
+	)
+}
+
+func (a astJSON) VisitExpressionStmt(stmt *Expression) any {
+	return a.node("Expression", stmt.Span(), astField{"expression", a.expr(stmt.Expression)})
+}
+
+func (a astJSON) VisitForStmt(stmt *For) any {
+	return a.node("For", stmt.Span(),
+		astField{"label", a.label(stmt.Label)},
+		astField{"initializer", a.stmt(stmt.Initializer)},
+		astField{"condition", a.expr(stmt.Condition)},
+		astField{"increment", a.expr(stmt.Increment)},
+		astField{"body", a.stmt(stmt.Body)},
+	)
+}
+
+func (a astJSON) VisitFunctionStmt(stmt *Function) any {
+	params := make([]string, 0, len(stmt.Params))
+	for _, param := range stmt.Params {
+		params = append(params, param.Lexeme)
+	}
+
+	return a.node("Function", stmt.Span(),
+		astField{"name", stmt.Name.Lexeme},
+		astField{"params", params},
+		astField{"body", a.statements(stmt.Body)},
+	)
+}
+
+func (a astJSON) VisitIfStmt(stmt *If) any {
+	return a.node("If", stmt.Span(),
+		astField{"condition", a.expr(stmt.Condition)},
+		astField{"then", a.stmt(stmt.ThenBranch)},
+		astField{"else", a.stmt(stmt.ElseBranch)},
+	)
+}
+
+func (a astJSON) VisitPrintStmt(stmt *Print) any {
+	return a.node("Print", stmt.Span(), astField{"expression", a.expr(stmt.Expression)})
+}
+
+func (a astJSON) VisitReturnStmt(stmt *Return) any {
+	return a.node("Return", stmt.Span(), astField{"value", a.expr(stmt.Value)})
+}
+
+func (a astJSON) VisitVariableStmtStmt(stmt *VariableStmt) any {
+	return a.node("Var", stmt.Span(),
+		astField{"name", stmt.Name.Lexeme},
+		astField{"initializer", a.expr(stmt.Initializer)},
+	)
+}
+
+func (a astJSON) VisitWhileStmt(stmt *While) any {
+	return a.node("While", stmt.Span(),
+		astField{"label", a.label(stmt.Label)},
+		astField{"condition", a.expr(stmt.Condition)},
+		astField{"body", a.stmt(stmt.Body)},
+	)
+}
+
+func (a astJSON) VisitAssignExpr(expr *Assign) any {
+	return a.node("Assign", expr.Span(),
+		astField{"name", expr.Name.Lexeme},
+		astField{"value", a.expr(expr.value)},
+	)
+}
+
+func (a astJSON) VisitTernaryExpr(expr *Ternary) any {
+	return a.node("Ternary", expr.Span(),
+		astField{"condition", a.expr(expr.Condition)},
+		astField{"then", a.expr(expr.TrueExpr)},
+		astField{"else", a.expr(expr.FalseExpr)},
+	)
+}
+
+func (a astJSON) VisitBinaryExpr(expr *Binary) any {
+	return a.node("Binary", expr.Span(),
+		astField{"operator", expr.Operator.Lexeme},
+		astField{"left", a.expr(expr.Left)},
+		astField{"right", a.expr(expr.Right)},
+	)
+}
+
+func (a astJSON) VisitCallExpr(expr *Call) any {
+	return a.node("Call", expr.Span(),
+		astField{"callee", a.expr(expr.Callee)},
+		astField{"arguments", a.exprs(expr.Arguments)},
+	)
+}
+
+func (a astJSON) VisitGetExpr(expr *Get) any {
+	return a.node("Get", expr.Span(),
+		astField{"object", a.expr(expr.Object)},
+		astField{"name", expr.Name.Lexeme},
+	)
+}
+
+func (a astJSON) VisitGroupingExpr(expr *Grouping) any {
+	return a.node("Grouping", expr.Span(), astField{"expression", a.expr(expr.Expression)})
+}
+
+func (a astJSON) VisitIndexExpr(expr *Index) any {
+	return a.node("Index", expr.Span(),
+		astField{"object", a.expr(expr.Object)},
+		astField{"index", a.expr(expr.Index)},
+	)
+}
+
+func (a astJSON) VisitListExpr(expr *List) any {
+	return a.node("List", expr.Span(), astField{"elements", a.exprs(expr.Elements)})
+}
+
+func (a astJSON) VisitLiteralExpr(expr *Literal) any {
+	return a.node("Literal", expr.Span(), astField{"value", expr.Value})
+}
+
+func (a astJSON) VisitLogicalExpr(expr *Logical) any {
+	return a.node("Logical", expr.Span(),
+		astField{"operator", expr.Operator.Lexeme},
+		astField{"left", a.expr(expr.Left)},
+		astField{"right", a.expr(expr.Right)},
+	)
+}
+
+func (a astJSON) VisitMapExpr(expr *Map) any {
+	entries := make([]any, 0, len(expr.Keys))
+	for i, key := range expr.Keys {
+		entries = append(entries, astNode{{"key", a.expr(key)}, {"value", a.expr(expr.Values[i])}})
+	}
+
+	return a.node("Map", expr.Span(), astField{"entries", entries})
+}
+
+func (a astJSON) VisitSetExpr(expr *Set) any {
+	return a.node("Set", expr.Span(),
+		astField{"object", a.expr(expr.Object)},
+		astField{"name", expr.Name.Lexeme},
+		astField{"value", a.expr(expr.Value)},
+	)
+}
+
+func (a astJSON) VisitSetIndexExpr(expr *SetIndex) any {
+	return a.node("SetIndex", expr.Span(),
+		astField{"object", a.expr(expr.Object)},
+		astField{"index", a.expr(expr.Index)},
+		astField{"value", a.expr(expr.Value)},
+	)
+}
+
+func (a astJSON) VisitSliceExpr(expr *Slice) any {
+	return a.node("Slice", expr.Span(),
+		astField{"object", a.expr(expr.Object)},
+		astField{"start", a.expr(expr.Start)},
+		astField{"end", a.expr(expr.End)},
+	)
+}
+
+func (a astJSON) VisitToStringExpr(expr *ToString) any {
+	return a.node("ToString", expr.Span(), astField{"expression", a.expr(expr.Expression)})
+}
+
+func (a astJSON) VisitSuperExpr(expr *Super) any {
+	return a.node("Super", expr.Span(), astField{"method", expr.Method.Lexeme})
+}
+
+func (a astJSON) VisitThisExpr(expr *This) any {
+	return a.node("This", expr.Span())
+}
+
+func (a astJSON) VisitUnaryExpr(expr *Unary) any {
+	return a.node("Unary", expr.Span(),
+		astField{"operator", expr.Operator.Lexeme},
+		astField{"right", a.expr(expr.Right)},
+	)
+}
+
+func (a astJSON) VisitVariableExprExpr(expr *VariableExpr) any {
+	return a.node("Variable", expr.Span(), astField{"name", expr.Name.Lexeme})
+}
@@ -0,0 +1,255 @@
+package lox
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// AstPrinter prints the AST as S-expressions, one statement per line.
+// Missing parts of a for loop or a slice are printed as "_".
+type AstPrinter struct{}
+
+func (t AstPrinter) VisitBlockStmt(stmt *Block) any {
+	return t.parenthesizeStmts("block", stmt.Statements...)
+}
+
+func (t AstPrinter) VisitBreakStmt(stmt *Break) any {
+	if stmt.Label.Lexeme == "" {
+		return "(break)"
+	}
+
+	return "(break " + stmt.Label.Lexeme + ")"
+}
+
+func (t AstPrinter) VisitContinueStmt(stmt *Continue) any {
+	if stmt.Label.Lexeme == "" {
+		return "(continue)"
+	}
+
+	return "(continue " + stmt.Label.Lexeme + ")"
+}
+
+func (t AstPrinter) VisitClassStmt(stmt *Class) any {
+	name := "class " + stmt.Name.Lexeme
+	if stmt.Superclass != nil {
+		name += " < " + t.Print(stmt.Superclass)
+	}
+
+	methods := make([]Stmt, 0, len(stmt.Methods))
+	for _, method := range stmt.Methods {
+		methods = append(methods, method)
+	}
+
+	return t.parenthesizeStmts(name, methods...)
+}
+
+func (t AstPrinter) VisitExpressionStmt(stmt *Expression) any {
+	return t.Parenthesize(";", stmt.Expression)
+}
+
+func (t AstPrinter) VisitForStmt(stmt *For) any {
+	parts := []string{t.loop("for", stmt.Label), "_", "_", "_", t.PrintStmt(stmt.Body)}
+	if stmt.Initializer != nil {
+		parts[1] = t.PrintStmt(stmt.Initializer)
+	}
+	if stmt.Condition != nil {
+		parts[2] = t.Print(stmt.Condition)
+	}
+	if stmt.Increment != nil {
+		parts[3] = t.Print(stmt.Increment)
+	}
+
+	return "(" + strings.Join(parts, " ") + ")"
+}
+
+func (t AstPrinter) VisitFunctionStmt(stmt *Function) any {
+	params := make([]string, 0, len(stmt.Params))
+	for _, param := range stmt.Params {
+		params = append(params, param.Lexeme)
+	}
+
+	return t.parenthesizeStmts("fun "+stmt.Name.Lexeme+" ("+strings.Join(params, " ")+")", stmt.Body...)
+}
+
+func (t AstPrinter) VisitIfStmt(stmt *If) any {
+	if stmt.ElseBranch == nil {
+		return "(if " + t.Print(stmt.Condition) + " " + t.PrintStmt(stmt.ThenBranch) + ")"
+	}
+
+	return "(if-else " + t.Print(stmt.Condition) + " " + t.PrintStmt(stmt.ThenBranch) + " " + t.PrintStmt(stmt.ElseBranch) + ")"
+}
+
+func (t AstPrinter) VisitPrintStmt(stmt *Print) any {
+	return t.Parenthesize("print", stmt.Expression)
+}
+
+func (t AstPrinter) VisitReturnStmt(stmt *Return) any {
+	if stmt.Value == nil {
+		return "(return)"
+	}
+
+	return t.Parenthesize("return", stmt.Value)
+}
+
+func (t AstPrinter) VisitVariableStmtStmt(stmt *VariableStmt) any {
+	if stmt.Initializer == nil {
+		return "(var " + stmt.Name.Lexeme + ")"
+	}
+
+	return t.Parenthesize("var "+stmt.Name.Lexeme, stmt.Initializer)
+}
+
+func (t AstPrinter) VisitWhileStmt(stmt *While) any {
+	return "(" + t.loop("while", stmt.Label) + " " + t.Print(stmt.Condition) + " " + t.PrintStmt(stmt.Body) + ")"
+}
+
+// loop names a loop statement, prefixed with its label if it has one.
+func (t AstPrinter) loop(keyword string, label Token) string {
+	if label.Lexeme == "" {
+		return keyword
+	}
+
+	return label.Lexeme + ": " + keyword
+}
+
+func (t AstPrinter) VisitAssignExpr(assign *Assign) any {
+	return t.Parenthesize("= "+assign.Name.Lexeme, assign.value)
+}
+
+func (t AstPrinter) VisitTernaryExpr(ternary *Ternary) any {
+	return t.Parenthesize("?", ternary.Condition, ternary.TrueExpr, ternary.FalseExpr)
+}
+
+func (t AstPrinter) VisitBinaryExpr(binary *Binary) any {
+	return t.Parenthesize(binary.Operator.Lexeme, binary.Left, binary.Right)
+}
+
+func (t AstPrinter) VisitCallExpr(call *Call) any {
+	return t.Parenthesize("call", append([]Expr{call.Callee}, call.Arguments...)...)
+}
+
+func (t AstPrinter) VisitGetExpr(get *Get) any {
+	return "(. " + t.Print(get.Object) + " " + get.Name.Lexeme + ")"
+}
+
+func (t AstPrinter) VisitGroupingExpr(grouping *Grouping) any {
+	return t.Parenthesize("group", grouping.Expression)
+}
+
+func (t AstPrinter) VisitIndexExpr(index *Index) any {
+	return t.Parenthesize("index", index.Object, index.Index)
+}
+
+func (t AstPrinter) VisitListExpr(list *List) any {
+	return t.Parenthesize("list", list.Elements...)
+}
+
+func (t AstPrinter) VisitLiteralExpr(literal *Literal) any {
+	if literal.Value == nil {
+		return "nil"
+	}
+	switch l := literal.Value.(type) {
+	case float64:
+		return FormatNumber(l)
+	case string:
+		// Quoted, so that "" and "a b" can't be mistaken for nothing or for
+		// two symbols.
+		return strconv.Quote(l)
+	default:
+		return fmt.Sprint(l)
+	}
+}
+
+func (t AstPrinter) VisitLogicalExpr(logical *Logical) any {
+	return t.Parenthesize(logical.Operator.Lexeme, logical.Left, logical.Right)
+}
+
+func (t AstPrinter) VisitMapExpr(m *Map) any {
+	entries := make([]Expr, 0, 2*len(m.Keys))
+	for i, key := range m.Keys {
+		entries = append(entries, key, m.Values[i])
+	}
+
+	return t.Parenthesize("map", entries...)
+}
+
+func (t AstPrinter) VisitSetExpr(set *Set) any {
+	return "(= " + t.Print(set.Object) + " " + set.Name.Lexeme + " " + t.Print(set.Value) + ")"
+}
+
+func (t AstPrinter) VisitSetIndexExpr(set *SetIndex) any {
+	return t.Parenthesize("set-index", set.Object, set.Index, set.Value)
+}
+
+func (t AstPrinter) VisitSliceExpr(slice *Slice) any {
+	parts := []string{"slice", t.Print(slice.Object), "_", "_"}
+	if slice.Start != nil {
+		parts[2] = t.Print(slice.Start)
+	}
+	if slice.End != nil {
+		parts[3] = t.Print(slice.End)
+	}
+
+	return "(" + strings.Join(parts, " ") + ")"
+}
+
+func (t AstPrinter) VisitToStringExpr(toString *ToString) any {
+	return t.Parenthesize("str", toString.Expression)
+}
+
+func (t AstPrinter) VisitSuperExpr(super *Super) any {
+	return "(super " + super.Method.Lexeme + ")"
+}
+
+func (t AstPrinter) VisitThisExpr(this *This) any {
+	return "this"
+}
+
+func (t AstPrinter) VisitUnaryExpr(unary *Unary) any {
+	return t.Parenthesize(unary.Operator.Lexeme, unary.Right)
+}
+
+func (t AstPrinter) VisitVariableExprExpr(variable *VariableExpr) any {
+	return variable.Name.Lexeme
+}
+
+func (t AstPrinter) Parenthesize(name string, exprs ...Expr) string {
+	var sb strings.Builder
+	sb.WriteString("(")
+	sb.WriteString(name)
+	for _, expr := range exprs {
+		sb.WriteString(" ")
+		sb.WriteString(expr.Accept(t).(string))
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
+func (t AstPrinter) parenthesizeStmts(name string, stmts ...Stmt) string {
+	var sb strings.Builder
+	sb.WriteString("(")
+	sb.WriteString(name)
+	for _, stmt := range stmts {
+		sb.WriteString(" ")
+		sb.WriteString(stmt.Accept(t).(string))
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
+func (t AstPrinter) Print(expr Expr) string {
+	return expr.Accept(t).(string)
+}
+
+func (t AstPrinter) PrintStmt(stmt Stmt) string {
+	return stmt.Accept(t).(string)
+}
+
+func NewAstPrinter() AstPrinter {
+	return AstPrinter{}
+}
+
+func PrintAst(expr Expr) string {
+	return NewAstPrinter().Print(expr)
+}
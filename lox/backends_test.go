@@ -0,0 +1,84 @@
+package lox_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/michalzarsm/lox-interpreter/lox"
+)
+
+// TestBackendsAgree runs every program in testdata/backends on both
+// backends and checks that they print the same output and fail with the
+// same errors, reported at the same places.
+func TestBackendsAgree(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "backends", "*.lox"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no programs in testdata/backends")
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			source, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tree := runOn(lox.BackendTree, source)
+			vm := runOn(lox.BackendVM, source)
+
+			if tree.output == "" && tree.errors == "" {
+				t.Fatal("the program neither prints nor fails")
+			}
+			if tree.output != vm.output {
+				t.Errorf("output differs\ntree:\n%s\nvm:\n%s", tree.output, vm.output)
+			}
+			if tree.errors != vm.errors {
+				t.Errorf("errors differ\ntree:\n%s\nvm:\n%s", tree.errors, vm.errors)
+			}
+		})
+	}
+}
+
+type backendResult struct {
+	output string
+	errors string
+}
+
+func runOn(backend lox.Backend, source []byte) backendResult {
+	var stdout bytes.Buffer
+
+	runtime := lox.NewRuntime()
+	runtime.Stdout = &stdout
+	runtime.Backend = backend
+	err := runtime.Run(bytes.NewReader(source))
+
+	return backendResult{output: stdout.String(), errors: describeErrors(err)}
+}
+
+// describeErrors lists the errors with their spans, one per line.
+func describeErrors(err error) string {
+	var errs lox.Errors
+	if !errors.As(err, &errs) {
+		if err != nil {
+			return err.Error()
+		}
+
+		return ""
+	}
+
+	lines := make([]string, 0, len(errs))
+	for _, e := range errs {
+		span := e.Token.Span
+		lines = append(lines, fmt.Sprintf("%d:%d-%d:%d %s", span.Start.Line, span.Start.Column, span.End.Line, span.End.Column, e.Message))
+	}
+
+	return strings.Join(lines, "\n")
+}
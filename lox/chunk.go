@@ -0,0 +1,139 @@
+package lox
+
+import "sort"
+
+type OpCode byte
+
+const (
+	OP_CONSTANT OpCode = iota
+	OP_NIL
+	OP_TRUE
+	OP_FALSE
+	OP_POP
+	OP_GET_LOCAL
+	OP_SET_LOCAL
+	OP_GET_GLOBAL
+	OP_DEFINE_GLOBAL
+	OP_SET_GLOBAL
+	OP_GET_UPVALUE
+	OP_SET_UPVALUE
+	OP_GET_PROPERTY
+	OP_SET_PROPERTY
+	OP_GET_SUPER
+	OP_EQUAL
+	OP_NOT_EQUAL
+	OP_GREATER
+	OP_GREATER_EQUAL
+	OP_LESS
+	OP_LESS_EQUAL
+	OP_ADD
+	OP_SUBTRACT
+	OP_MULTIPLY
+	OP_DIVIDE
+	OP_NOT
+	OP_NEGATE
+	OP_PRINT
+	OP_JUMP
+	OP_JUMP_IF_FALSE
+	OP_LOOP
+	OP_CALL
+	OP_CLOSURE
+	OP_CLOSE_UPVALUE
+	OP_RETURN
+	OP_CLASS
+	OP_INHERIT
+	OP_METHOD
+	OP_BUILD_LIST
+	OP_GET_INDEX
+	OP_SET_INDEX
+	OP_SLICE
+	OP_BUILD_MAP
+	OP_STRINGIFY
+)
+
+// LineStart marks the offset of the first instruction compiled from a new
+// source line. Every instruction up to the next LineStart comes from the
+// same line.
+type LineStart struct {
+	Offset int
+	Line   int
+}
+
+// SpanStart marks the offset of the first instruction compiled from a new
+// span of source, in the same way as LineStart does for lines.
+type SpanStart struct {
+	Offset int
+	Span   Span
+}
+
+// Chunk is a sequence of bytecode instructions together with the constants
+// they refer to and the source lines and spans they were compiled from.
+type Chunk struct {
+	Code      []byte
+	Constants []Value
+	Lines     []LineStart
+	Spans     []SpanStart
+}
+
+func newChunk() *Chunk {
+	return &Chunk{
+		Code:      make([]byte, 0),
+		Constants: make([]Value, 0),
+		Lines:     make([]LineStart, 0),
+		Spans:     make([]SpanStart, 0),
+	}
+}
+
+func (c *Chunk) write(b byte, line int, span Span) {
+	if len(c.Lines) == 0 || c.Lines[len(c.Lines)-1].Line != line {
+		c.Lines = append(c.Lines, LineStart{Offset: len(c.Code), Line: line})
+	}
+
+	if len(c.Spans) == 0 || c.Spans[len(c.Spans)-1].Span != span {
+		c.Spans = append(c.Spans, SpanStart{Offset: len(c.Code), Span: span})
+	}
+
+	c.Code = append(c.Code, b)
+}
+
+// addConstant returns the index of value in the constant pool, adding it
+// if an equal number or string isn't there already.
+func (c *Chunk) addConstant(value Value) int {
+	switch value.(type) {
+	case float64, string:
+		for i, constant := range c.Constants {
+			if constant == value {
+				return i
+			}
+		}
+	}
+
+	c.Constants = append(c.Constants, value)
+	return len(c.Constants) - 1
+}
+
+// getLine returns the source line of the instruction at offset.
+func (c *Chunk) getLine(offset int) int {
+	i := sort.Search(len(c.Lines), func(i int) bool {
+		return c.Lines[i].Offset > offset
+	})
+
+	if i == 0 {
+		return 0
+	}
+
+	return c.Lines[i-1].Line
+}
+
+// getSpan returns the source span of the instruction at offset.
+func (c *Chunk) getSpan(offset int) Span {
+	i := sort.Search(len(c.Spans), func(i int) bool {
+		return c.Spans[i].Offset > offset
+	})
+
+	if i == 0 {
+		return Span{}
+	}
+
+	return c.Spans[i-1].Span
+}
@@ -0,0 +1,58 @@
+package lox
+
+// LoxClass is shared by both backends. The Interpreter fills in methods,
+// while the VM fills in closures, copying inherited ones down from the
+// superclass so lookups don't have to walk the chain.
+type LoxClass struct {
+	name       string
+	superclass *LoxClass
+	methods    map[string]*LoxFunction
+	closures   map[string]*Closure
+}
+
+func newLoxClass(name string, superclass *LoxClass, methods map[string]*LoxFunction) *LoxClass {
+	return &LoxClass{
+		name:       name,
+		superclass: superclass,
+		methods:    methods,
+		closures:   make(map[string]*Closure),
+	}
+}
+
+// findMethod looks the method up on the class and then on each superclass
+// in turn. It returns nil when no class in the chain defines it.
+func (c *LoxClass) findMethod(name string) *LoxFunction {
+	if method, ok := c.methods[name]; ok {
+		return method
+	}
+
+	if c.superclass != nil {
+		return c.superclass.findMethod(name)
+	}
+
+	return nil
+}
+
+func (c *LoxClass) arity() int {
+	initializer := c.findMethod("init")
+	if initializer == nil {
+		return 0
+	}
+
+	return initializer.arity()
+}
+
+func (c *LoxClass) call(interpreter *Interpreter, paren Token, arguments []any) any {
+	instance := newLoxInstance(c)
+
+	initializer := c.findMethod("init")
+	if initializer != nil {
+		initializer.bind(instance).call(interpreter, paren, arguments)
+	}
+
+	return instance
+}
+
+func (c *LoxClass) String() string {
+	return c.name
+}
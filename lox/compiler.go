@@ -0,0 +1,862 @@
+package lox
+
+import "math"
+
+type localVariable struct {
+	name       string
+	depth      int
+	isCaptured bool
+}
+
+type upvalueReference struct {
+	index   int
+	isLocal bool
+}
+
+// functionCompiler holds the state of one function being compiled. They
+// form a stack through enclosing that mirrors the nesting of function
+// declarations in the source.
+type functionCompiler struct {
+	enclosing    *functionCompiler
+	function     *CompiledFunction
+	functionType FunctionType
+	locals       []localVariable
+	upvalues     []upvalueReference
+	scopeDepth   int
+	loops        []*loopCompiler
+}
+
+// loopCompiler tracks a loop being compiled. Break and continue statements
+// jump forward out of the body and leave their jumps here to be patched
+// once the loop's exit and the start of its next iteration are known.
+type loopCompiler struct {
+	label      string
+	scopeDepth int
+	breaks     []int
+	continues  []int
+}
+
+type classCompiler struct {
+	enclosing     *classCompiler
+	hasSuperclass bool
+}
+
+// Compiler turns the statements produced by the Parser into bytecode for
+// the VM. It expects the statements to have passed the Resolver, so it
+// only reports the limits of the bytecode format as errors.
+type Compiler struct {
+	Lox          *Lox
+	current      *functionCompiler
+	currentClass *classCompiler
+	line         int
+	span         Span
+}
+
+func newCompiler(lox *Lox) *Compiler {
+	return &Compiler{
+		Lox:  lox,
+		line: 1,
+	}
+}
+
+// compile compiles a whole program into the function for its top-level
+// script. If the last statement is an expression statement, the script
+// returns its value.
+func (c *Compiler) compile(statements []Stmt) *CompiledFunction {
+	c.beginFunction(FunctionTypeNone, "")
+
+	for i, statement := range statements {
+		if expression, ok := statement.(*Expression); ok && i == len(statements)-1 {
+			c.compileExpr(expression.Expression)
+			c.emitOp(OP_RETURN)
+			function, _ := c.endFunction()
+			return function
+		}
+
+		c.compileStmt(statement)
+	}
+
+	c.emitReturn()
+	function, _ := c.endFunction()
+	return function
+}
+
+func (c *Compiler) compileStmt(stmt Stmt) {
+	stmt.Accept(c)
+}
+
+func (c *Compiler) compileExpr(expr Expr) {
+	expr.Accept(c)
+}
+
+func (c *Compiler) beginFunction(functionType FunctionType, name string) {
+	compiler := &functionCompiler{
+		enclosing:    c.current,
+		function:     newCompiledFunction(name),
+		functionType: functionType,
+		locals:       make([]localVariable, 0),
+		upvalues:     make([]upvalueReference, 0),
+	}
+
+	// Slot zero holds the function being called, or the receiver in
+	// methods, where it can be reached as "this".
+	slotZero := ""
+	if functionType == FunctionTypeMethod || functionType == FunctionTypeInitializer {
+		slotZero = "this"
+	}
+	compiler.locals = append(compiler.locals, localVariable{name: slotZero, depth: 0})
+
+	c.current = compiler
+}
+
+func (c *Compiler) endFunction() (*CompiledFunction, []upvalueReference) {
+	compiler := c.current
+	compiler.function.UpvalueCount = len(compiler.upvalues)
+	c.current = compiler.enclosing
+
+	return compiler.function, compiler.upvalues
+}
+
+func (c *Compiler) function(declaration *Function, functionType FunctionType) {
+	c.beginFunction(functionType, declaration.Name.Lexeme)
+	c.beginScope()
+
+	for _, param := range declaration.Params {
+		c.declareVariable(param)
+		c.markInitialized()
+	}
+	c.current.function.Arity = len(declaration.Params)
+
+	for _, statement := range declaration.Body {
+		c.compileStmt(statement)
+	}
+	c.emitReturn()
+
+	function, upvalues := c.endFunction()
+
+	c.locate(declaration.Name)
+	c.emitOpShort(OP_CLOSURE, c.makeConstant(function, declaration.Name))
+	for _, upvalue := range upvalues {
+		if upvalue.isLocal {
+			c.emitByte(1)
+		} else {
+			c.emitByte(0)
+		}
+		c.emitByte(byte(upvalue.index >> 8))
+		c.emitByte(byte(upvalue.index))
+	}
+}
+
+// locate makes token the source location of the code emitted next.
+func (c *Compiler) locate(token Token) {
+	c.line = token.Line
+	c.span = token.Span
+}
+
+func (c *Compiler) chunk() *Chunk {
+	return c.current.function.Chunk
+}
+
+func (c *Compiler) emitByte(b byte) {
+	c.chunk().write(b, c.line, c.span)
+}
+
+func (c *Compiler) emitOp(op OpCode) {
+	c.emitByte(byte(op))
+}
+
+func (c *Compiler) emitOpByte(op OpCode, operand byte) {
+	c.emitOp(op)
+	c.emitByte(operand)
+}
+
+func (c *Compiler) emitOpShort(op OpCode, operand int) {
+	c.emitOp(op)
+	c.emitByte(byte(operand >> 8))
+	c.emitByte(byte(operand))
+}
+
+// emitReturn emits the implicit return at the end of a function body.
+// Initializers always return the instance they were called on.
+func (c *Compiler) emitReturn() {
+	if c.current.functionType == FunctionTypeInitializer {
+		c.emitOpShort(OP_GET_LOCAL, 0)
+	} else {
+		c.emitOp(OP_NIL)
+	}
+
+	c.emitOp(OP_RETURN)
+}
+
+// emitJump emits a forward jump with a placeholder offset and returns the
+// position of the offset so patchJump can fill it in.
+func (c *Compiler) emitJump(op OpCode) int {
+	c.emitOpShort(op, 0xffff)
+	return len(c.chunk().Code) - 2
+}
+
+func (c *Compiler) patchJump(offset int, token Token) {
+	jump := len(c.chunk().Code) - offset - 2
+	if jump > math.MaxUint16 {
+		c.error(token, "Too much code to jump over.")
+	}
+
+	c.chunk().Code[offset] = byte(jump >> 8)
+	c.chunk().Code[offset+1] = byte(jump)
+}
+
+func (c *Compiler) emitLoop(loopStart int, token Token) {
+	offset := len(c.chunk().Code) - loopStart + 3
+	if offset > math.MaxUint16 {
+		c.error(token, "Loop body too large.")
+	}
+
+	c.emitOpShort(OP_LOOP, offset)
+}
+
+func (c *Compiler) makeConstant(value Value, token Token) int {
+	constant := c.chunk().addConstant(value)
+	if constant > math.MaxUint16 {
+		c.error(token, "Too many constants in one chunk.")
+		return 0
+	}
+
+	return constant
+}
+
+func (c *Compiler) identifierConstant(name Token) int {
+	return c.makeConstant(name.Lexeme, name)
+}
+
+func (c *Compiler) beginScope() {
+	c.current.scopeDepth += 1
+}
+
+func (c *Compiler) endScope() {
+	c.current.scopeDepth -= 1
+
+	locals := c.current.locals
+	for len(locals) > 0 && locals[len(locals)-1].depth > c.current.scopeDepth {
+		if locals[len(locals)-1].isCaptured {
+			c.emitOp(OP_CLOSE_UPVALUE)
+		} else {
+			c.emitOp(OP_POP)
+		}
+		locals = locals[:len(locals)-1]
+	}
+	c.current.locals = locals
+}
+
+func (c *Compiler) beginLoop(label Token) *loopCompiler {
+	loop := &loopCompiler{label: label.Lexeme, scopeDepth: c.current.scopeDepth}
+	c.current.loops = append(c.current.loops, loop)
+
+	return loop
+}
+
+func (c *Compiler) endLoop() {
+	c.current.loops = c.current.loops[:len(c.current.loops)-1]
+}
+
+// findLoop returns the loop a break or continue applies to: the innermost
+// one if label is empty, otherwise the one with that label. The Parser has
+// made sure it exists.
+func (c *Compiler) findLoop(label Token) *loopCompiler {
+	loops := c.current.loops
+	for i := len(loops) - 1; i >= 0; i-- {
+		if label.Lexeme == "" || loops[i].label == label.Lexeme {
+			return loops[i]
+		}
+	}
+
+	return loops[len(loops)-1]
+}
+
+// discardLocals emits the instructions that pop the locals declared deeper
+// than depth, as endScope does, but keeps them declared, since the code
+// that follows a jump out of their scope still belongs to it.
+func (c *Compiler) discardLocals(depth int) {
+	locals := c.current.locals
+	for i := len(locals) - 1; i >= 0 && locals[i].depth > depth; i-- {
+		if locals[i].isCaptured {
+			c.emitOp(OP_CLOSE_UPVALUE)
+		} else {
+			c.emitOp(OP_POP)
+		}
+	}
+}
+
+// declareVariable adds a local variable to the current scope. Globals are
+// late bound and need no declaration.
+func (c *Compiler) declareVariable(name Token) {
+	if c.current.scopeDepth == 0 {
+		return
+	}
+
+	if len(c.current.locals) > math.MaxUint16 {
+		c.error(name, "Too many local variables in function.")
+		return
+	}
+
+	c.current.locals = append(c.current.locals, localVariable{name: name.Lexeme, depth: -1})
+}
+
+func (c *Compiler) markInitialized() {
+	if c.current.scopeDepth == 0 {
+		return
+	}
+
+	c.current.locals[len(c.current.locals)-1].depth = c.current.scopeDepth
+}
+
+func (c *Compiler) defineVariable(name Token) {
+	if c.current.scopeDepth > 0 {
+		c.markInitialized()
+		return
+	}
+
+	c.locate(name)
+	c.emitOpShort(OP_DEFINE_GLOBAL, c.identifierConstant(name))
+}
+
+func resolveLocal(compiler *functionCompiler, name string) int {
+	for i := len(compiler.locals) - 1; i >= 0; i-- {
+		if compiler.locals[i].name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (c *Compiler) resolveUpvalue(compiler *functionCompiler, name Token) int {
+	if compiler.enclosing == nil {
+		return -1
+	}
+
+	local := resolveLocal(compiler.enclosing, name.Lexeme)
+	if local != -1 {
+		compiler.enclosing.locals[local].isCaptured = true
+		return c.addUpvalue(compiler, local, true, name)
+	}
+
+	upvalue := c.resolveUpvalue(compiler.enclosing, name)
+	if upvalue != -1 {
+		return c.addUpvalue(compiler, upvalue, false, name)
+	}
+
+	return -1
+}
+
+func (c *Compiler) addUpvalue(compiler *functionCompiler, index int, isLocal bool, name Token) int {
+	for i, upvalue := range compiler.upvalues {
+		if upvalue.index == index && upvalue.isLocal == isLocal {
+			return i
+		}
+	}
+
+	if len(compiler.upvalues) > math.MaxUint16 {
+		c.error(name, "Too many closure variables in function.")
+		return 0
+	}
+
+	compiler.upvalues = append(compiler.upvalues, upvalueReference{index: index, isLocal: isLocal})
+	return len(compiler.upvalues) - 1
+}
+
+// getVariable emits the instruction that pushes the variable's value.
+func (c *Compiler) getVariable(name Token) {
+	c.locate(name)
+
+	if arg := resolveLocal(c.current, name.Lexeme); arg != -1 {
+		c.emitOpShort(OP_GET_LOCAL, arg)
+	} else if arg := c.resolveUpvalue(c.current, name); arg != -1 {
+		c.emitOpShort(OP_GET_UPVALUE, arg)
+	} else {
+		c.emitOpShort(OP_GET_GLOBAL, c.identifierConstant(name))
+	}
+}
+
+// setVariable emits the instruction that stores the value on top of the
+// stack in the variable, leaving the value on the stack.
+func (c *Compiler) setVariable(name Token) {
+	c.locate(name)
+
+	if arg := resolveLocal(c.current, name.Lexeme); arg != -1 {
+		c.emitOpShort(OP_SET_LOCAL, arg)
+	} else if arg := c.resolveUpvalue(c.current, name); arg != -1 {
+		c.emitOpShort(OP_SET_UPVALUE, arg)
+	} else {
+		c.emitOpShort(OP_SET_GLOBAL, c.identifierConstant(name))
+	}
+}
+
+func (c *Compiler) error(token Token, message string) {
+	c.Lox.errors = append(c.Lox.errors, Error{Type: SyntaxError, Token: token, Message: message, ExitCode: 65})
+}
+
+func (c *Compiler) VisitBlockStmt(stmt *Block) any {
+	c.beginScope()
+	for _, statement := range stmt.Statements {
+		c.compileStmt(statement)
+	}
+	c.endScope()
+
+	return nil
+}
+
+func (c *Compiler) VisitBreakStmt(stmt *Break) any {
+	c.locate(stmt.Keyword)
+
+	loop := c.findLoop(stmt.Label)
+	c.discardLocals(loop.scopeDepth)
+	loop.breaks = append(loop.breaks, c.emitJump(OP_JUMP))
+
+	return nil
+}
+
+func (c *Compiler) VisitContinueStmt(stmt *Continue) any {
+	c.locate(stmt.Keyword)
+
+	loop := c.findLoop(stmt.Label)
+	c.discardLocals(loop.scopeDepth)
+	loop.continues = append(loop.continues, c.emitJump(OP_JUMP))
+
+	return nil
+}
+
+func (c *Compiler) VisitClassStmt(stmt *Class) any {
+	c.locate(stmt.Name)
+	nameConstant := c.identifierConstant(stmt.Name)
+	c.declareVariable(stmt.Name)
+
+	c.emitOpShort(OP_CLASS, nameConstant)
+	c.defineVariable(stmt.Name)
+
+	class := &classCompiler{enclosing: c.currentClass}
+	c.currentClass = class
+
+	if stmt.Superclass != nil {
+		superclass := stmt.Superclass.(*VariableExpr)
+
+		c.beginScope()
+		c.declareVariable(Token{Type: SUPER, Lexeme: "super", Line: superclass.Name.Line})
+		c.markInitialized()
+
+		c.getVariable(superclass.Name)
+		c.getVariable(stmt.Name)
+		c.locate(superclass.Name)
+		c.emitOp(OP_INHERIT)
+		class.hasSuperclass = true
+	}
+
+	c.getVariable(stmt.Name)
+
+	for _, method := range stmt.Methods {
+		functionType := FunctionTypeMethod
+		if method.Name.Lexeme == "init" {
+			functionType = FunctionTypeInitializer
+		}
+
+		c.function(method, functionType)
+		c.emitOpShort(OP_METHOD, c.identifierConstant(method.Name))
+	}
+
+	c.emitOp(OP_POP)
+
+	if class.hasSuperclass {
+		c.endScope()
+	}
+
+	c.currentClass = class.enclosing
+
+	return nil
+}
+
+func (c *Compiler) VisitExpressionStmt(stmt *Expression) any {
+	c.compileExpr(stmt.Expression)
+	c.emitOp(OP_POP)
+
+	return nil
+}
+
+func (c *Compiler) VisitFunctionStmt(stmt *Function) any {
+	c.declareVariable(stmt.Name)
+	c.markInitialized()
+
+	c.function(stmt, FunctionTypeFunction)
+	c.defineVariable(stmt.Name)
+
+	return nil
+}
+
+func (c *Compiler) VisitIfStmt(stmt *If) any {
+	c.compileExpr(stmt.Condition)
+
+	thenJump := c.emitJump(OP_JUMP_IF_FALSE)
+	c.emitOp(OP_POP)
+	c.compileStmt(stmt.ThenBranch)
+
+	elseJump := c.emitJump(OP_JUMP)
+
+	c.patchJump(thenJump, Token{Line: c.line})
+	c.emitOp(OP_POP)
+
+	if stmt.ElseBranch != nil {
+		c.compileStmt(stmt.ElseBranch)
+	}
+	c.patchJump(elseJump, Token{Line: c.line})
+
+	return nil
+}
+
+func (c *Compiler) VisitPrintStmt(stmt *Print) any {
+	c.compileExpr(stmt.Expression)
+	c.emitOp(OP_PRINT)
+
+	return nil
+}
+
+func (c *Compiler) VisitReturnStmt(stmt *Return) any {
+	if stmt.Value == nil {
+		c.locate(stmt.Keyword)
+		c.emitReturn()
+		return nil
+	}
+
+	c.compileExpr(stmt.Value)
+	c.locate(stmt.Keyword)
+	c.emitOp(OP_RETURN)
+
+	return nil
+}
+
+func (c *Compiler) VisitVariableStmtStmt(stmt *VariableStmt) any {
+	c.locate(stmt.Name)
+	c.declareVariable(stmt.Name)
+
+	if stmt.Initializer != nil {
+		c.compileExpr(stmt.Initializer)
+	} else {
+		c.emitOp(OP_NIL)
+	}
+
+	c.defineVariable(stmt.Name)
+
+	return nil
+}
+
+func (c *Compiler) VisitForStmt(stmt *For) any {
+	c.beginScope()
+
+	if stmt.Initializer != nil {
+		c.compileStmt(stmt.Initializer)
+	}
+
+	loopStart := len(c.chunk().Code)
+
+	exitJump := -1
+	if stmt.Condition != nil {
+		c.compileExpr(stmt.Condition)
+		exitJump = c.emitJump(OP_JUMP_IF_FALSE)
+		c.emitOp(OP_POP)
+	}
+
+	loop := c.beginLoop(stmt.Label)
+	c.compileStmt(stmt.Body)
+	c.endLoop()
+
+	for _, jump := range loop.continues {
+		c.patchJump(jump, Token{Line: c.line})
+	}
+
+	if stmt.Increment != nil {
+		c.compileExpr(stmt.Increment)
+		c.emitOp(OP_POP)
+	}
+
+	c.emitLoop(loopStart, Token{Line: c.line})
+
+	if exitJump != -1 {
+		c.patchJump(exitJump, Token{Line: c.line})
+		c.emitOp(OP_POP)
+	}
+
+	for _, jump := range loop.breaks {
+		c.patchJump(jump, Token{Line: c.line})
+	}
+
+	c.endScope()
+
+	return nil
+}
+
+func (c *Compiler) VisitWhileStmt(stmt *While) any {
+	loopStart := len(c.chunk().Code)
+	c.compileExpr(stmt.Condition)
+
+	exitJump := c.emitJump(OP_JUMP_IF_FALSE)
+	c.emitOp(OP_POP)
+
+	loop := c.beginLoop(stmt.Label)
+	c.compileStmt(stmt.Body)
+	c.endLoop()
+
+	for _, jump := range loop.continues {
+		c.patchJump(jump, Token{Line: c.line})
+	}
+	c.emitLoop(loopStart, Token{Line: c.line})
+
+	c.patchJump(exitJump, Token{Line: c.line})
+	c.emitOp(OP_POP)
+
+	for _, jump := range loop.breaks {
+		c.patchJump(jump, Token{Line: c.line})
+	}
+
+	return nil
+}
+
+func (c *Compiler) VisitAssignExpr(expr *Assign) any {
+	c.compileExpr(expr.value)
+	c.setVariable(expr.Name)
+
+	return nil
+}
+
+func (c *Compiler) VisitTernaryExpr(expr *Ternary) any {
+	c.compileExpr(expr.Condition)
+
+	falseJump := c.emitJump(OP_JUMP_IF_FALSE)
+	c.emitOp(OP_POP)
+	c.compileExpr(expr.TrueExpr)
+
+	endJump := c.emitJump(OP_JUMP)
+
+	c.patchJump(falseJump, Token{Line: c.line})
+	c.emitOp(OP_POP)
+	c.compileExpr(expr.FalseExpr)
+	c.patchJump(endJump, Token{Line: c.line})
+
+	return nil
+}
+
+func (c *Compiler) VisitBinaryExpr(expr *Binary) any {
+	c.compileExpr(expr.Left)
+	c.compileExpr(expr.Right)
+
+	c.locate(expr.Operator)
+	switch expr.Operator.Type {
+	case BANG_EQUAL:
+		c.emitOp(OP_NOT_EQUAL)
+	case EQUAL_EQUAL:
+		c.emitOp(OP_EQUAL)
+	case GREATER:
+		c.emitOp(OP_GREATER)
+	case GREATER_EQUAL:
+		c.emitOp(OP_GREATER_EQUAL)
+	case LESS:
+		c.emitOp(OP_LESS)
+	case LESS_EQUAL:
+		c.emitOp(OP_LESS_EQUAL)
+	case PLUS:
+		c.emitOp(OP_ADD)
+	case MINUS:
+		c.emitOp(OP_SUBTRACT)
+	case STAR:
+		c.emitOp(OP_MULTIPLY)
+	case SLASH:
+		c.emitOp(OP_DIVIDE)
+	}
+
+	return nil
+}
+
+func (c *Compiler) VisitCallExpr(expr *Call) any {
+	c.compileExpr(expr.Callee)
+
+	for _, argument := range expr.Arguments {
+		c.compileExpr(argument)
+	}
+
+	c.locate(expr.Paren)
+	c.emitOpByte(OP_CALL, byte(len(expr.Arguments)))
+
+	return nil
+}
+
+func (c *Compiler) VisitGetExpr(expr *Get) any {
+	c.compileExpr(expr.Object)
+
+	c.locate(expr.Name)
+	c.emitOpShort(OP_GET_PROPERTY, c.identifierConstant(expr.Name))
+
+	return nil
+}
+
+func (c *Compiler) VisitGroupingExpr(expr *Grouping) any {
+	c.compileExpr(expr.Expression)
+	return nil
+}
+
+func (c *Compiler) VisitIndexExpr(expr *Index) any {
+	c.compileExpr(expr.Object)
+	c.compileExpr(expr.Index)
+
+	c.locate(expr.Bracket)
+	c.emitOp(OP_GET_INDEX)
+
+	return nil
+}
+
+func (c *Compiler) VisitListExpr(expr *List) any {
+	for _, element := range expr.Elements {
+		c.compileExpr(element)
+	}
+
+	c.locate(expr.Bracket)
+	if len(expr.Elements) > math.MaxUint16 {
+		c.error(expr.Bracket, "Too many elements in list literal.")
+	}
+	c.emitOpShort(OP_BUILD_LIST, len(expr.Elements))
+
+	return nil
+}
+
+func (c *Compiler) VisitLiteralExpr(expr *Literal) any {
+	switch value := expr.Value.(type) {
+	case nil:
+		c.emitOp(OP_NIL)
+	case bool:
+		if value {
+			c.emitOp(OP_TRUE)
+		} else {
+			c.emitOp(OP_FALSE)
+		}
+	default:
+		c.emitOpShort(OP_CONSTANT, c.makeConstant(value, Token{Line: c.line}))
+	}
+
+	return nil
+}
+
+func (c *Compiler) VisitLogicalExpr(expr *Logical) any {
+	c.compileExpr(expr.Left)
+
+	c.locate(expr.Operator)
+	if expr.Operator.Type == OR {
+		elseJump := c.emitJump(OP_JUMP_IF_FALSE)
+		endJump := c.emitJump(OP_JUMP)
+
+		c.patchJump(elseJump, expr.Operator)
+		c.emitOp(OP_POP)
+
+		c.compileExpr(expr.Right)
+		c.patchJump(endJump, expr.Operator)
+	} else {
+		endJump := c.emitJump(OP_JUMP_IF_FALSE)
+
+		c.emitOp(OP_POP)
+		c.compileExpr(expr.Right)
+
+		c.patchJump(endJump, expr.Operator)
+	}
+
+	return nil
+}
+
+func (c *Compiler) VisitSetExpr(expr *Set) any {
+	c.compileExpr(expr.Object)
+	c.compileExpr(expr.Value)
+
+	c.locate(expr.Name)
+	c.emitOpShort(OP_SET_PROPERTY, c.identifierConstant(expr.Name))
+
+	return nil
+}
+
+// VisitMapExpr leaves each key on the stack just below its value.
+func (c *Compiler) VisitMapExpr(expr *Map) any {
+	for i, key := range expr.Keys {
+		c.compileExpr(key)
+		c.compileExpr(expr.Values[i])
+	}
+
+	c.locate(expr.Brace)
+	if len(expr.Keys) > math.MaxUint16 {
+		c.error(expr.Brace, "Too many entries in map literal.")
+	}
+	c.emitOpShort(OP_BUILD_MAP, len(expr.Keys))
+
+	return nil
+}
+
+func (c *Compiler) VisitSetIndexExpr(expr *SetIndex) any {
+	c.compileExpr(expr.Object)
+	c.compileExpr(expr.Index)
+	c.compileExpr(expr.Value)
+
+	c.locate(expr.Bracket)
+	c.emitOp(OP_SET_INDEX)
+
+	return nil
+}
+
+// VisitSliceExpr compiles a missing bound as nil, which the list reads as
+// the start or the end of the list.
+func (c *Compiler) VisitSliceExpr(expr *Slice) any {
+	c.compileExpr(expr.Object)
+	for _, bound := range []Expr{expr.Start, expr.End} {
+		if bound != nil {
+			c.compileExpr(bound)
+		} else {
+			c.emitOp(OP_NIL)
+		}
+	}
+
+	c.locate(expr.Bracket)
+	c.emitOp(OP_SLICE)
+
+	return nil
+}
+
+func (c *Compiler) VisitToStringExpr(expr *ToString) any {
+	c.compileExpr(expr.Expression)
+	c.emitOp(OP_STRINGIFY)
+
+	return nil
+}
+
+func (c *Compiler) VisitSuperExpr(expr *Super) any {
+	c.getVariable(Token{Type: THIS, Lexeme: "this", Line: expr.Keyword.Line})
+	c.getVariable(expr.Keyword)
+
+	c.locate(expr.Method)
+	c.emitOpShort(OP_GET_SUPER, c.identifierConstant(expr.Method))
+
+	return nil
+}
+
+func (c *Compiler) VisitThisExpr(expr *This) any {
+	c.getVariable(expr.Keyword)
+	return nil
+}
+
+func (c *Compiler) VisitUnaryExpr(expr *Unary) any {
+	c.compileExpr(expr.Right)
+
+	c.locate(expr.Operator)
+	switch expr.Operator.Type {
+	case BANG:
+		c.emitOp(OP_NOT)
+	case MINUS:
+		c.emitOp(OP_NEGATE)
+	}
+
+	return nil
+}
+
+func (c *Compiler) VisitVariableExprExpr(expr *VariableExpr) any {
+	c.getVariable(expr.Name)
+	return nil
+}
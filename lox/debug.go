@@ -0,0 +1,159 @@
+package lox
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Debugger is told about every statement before the tree-walking
+// interpreter executes it. The program doesn't continue until Step
+// returns, so a debugger pauses the program by blocking in Step. frames
+// holds the calls in progress, outermost first; it is only valid until
+// Step returns.
+type Debugger interface {
+	Step(stmt Stmt, frames []*StackFrame)
+}
+
+// StackFrame is a call in progress, as seen by a Debugger. The outermost
+// frame is the script itself.
+type StackFrame struct {
+	Name string
+
+	// Statement is the statement the frame is executing.
+	Statement Stmt
+
+	env     *Environment
+	callEnv *Environment
+	globals *Environment
+}
+
+// Variable is a named value shown by a debugger.
+type Variable struct {
+	Name  string
+	Value Value
+}
+
+// Scope is a group of variables a frame can see.
+type Scope struct {
+	Name      string
+	Variables []Variable
+}
+
+// Scopes walks the environment chain of the frame outwards and splits it
+// into the variables of the call itself, the variables its function closed
+// over, and the globals. Scopes with nothing in them are left out.
+func (f *StackFrame) Scopes() []Scope {
+	locals := make([]Variable, 0)
+	closure := make([]Variable, 0)
+
+	inCall := true
+	seen := make(map[string]bool)
+	for env := f.env; env != nil && env != f.globals; env = env.enclosing {
+		variables := env.variables()
+		for _, variable := range variables {
+			// An inner scope shadows the same name further out.
+			if seen[variable.Name] {
+				continue
+			}
+			seen[variable.Name] = true
+
+			if inCall {
+				locals = append(locals, variable)
+			} else {
+				closure = append(closure, variable)
+			}
+		}
+
+		if env == f.callEnv {
+			inCall = false
+		}
+	}
+
+	scopes := make([]Scope, 0, 3)
+	if len(locals) > 0 {
+		scopes = append(scopes, Scope{Name: "Locals", Variables: locals})
+	}
+	if len(closure) > 0 {
+		scopes = append(scopes, Scope{Name: "Closure", Variables: closure})
+	}
+	scopes = append(scopes, Scope{Name: "Globals", Variables: f.globals.variables()})
+
+	return scopes
+}
+
+// variables returns the variables defined directly in the environment,
+// sorted by name.
+func (e *Environment) variables() []Variable {
+	variables := make([]Variable, 0, len(e.values))
+	for name, value := range e.values {
+		variables = append(variables, Variable{Name: name, Value: value})
+	}
+
+	sort.Slice(variables, func(i, j int) bool {
+		return variables[i].Name < variables[j].Name
+	})
+
+	return variables
+}
+
+// Fields returns the fields of an instance sorted by name, the elements of
+// a list named by their index, the entries of a map named by their key, or
+// nil for any other value, so a debugger can expand it.
+func Fields(value Value) []Variable {
+	if list, ok := value.(*LoxList); ok {
+		elements := make([]Variable, 0, len(list.elements))
+		for i, element := range list.elements {
+			elements = append(elements, Variable{Name: strconv.Itoa(i), Value: element})
+		}
+
+		return elements
+	}
+
+	if m, ok := value.(*LoxMap); ok {
+		entries := make([]Variable, 0, len(m.keys))
+		for _, key := range m.keys {
+			entries = append(entries, Variable{Name: formatConstant(key), Value: m.entries[key]})
+		}
+
+		return entries
+	}
+
+	instance, ok := value.(*LoxInstance)
+	if !ok {
+		return nil
+	}
+
+	fields := make([]Variable, 0, len(instance.fields))
+	for name, field := range instance.fields {
+		fields = append(fields, Variable{Name: name, Value: field})
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Name < fields[j].Name
+	})
+
+	return fields
+}
+
+// Stringify formats a value the way print does.
+func Stringify(value Value) string {
+	return stringify(value)
+}
+
+// step reports the statement about to run to the debugger.
+func (i *Interpreter) step(stmt Stmt) {
+	frame := i.frames[len(i.frames)-1]
+	frame.Statement = stmt
+	frame.env = i.env
+
+	i.debugger.Step(stmt, i.frames)
+}
+
+// pushFrame records the start of a call whose parameters live in env.
+func (i *Interpreter) pushFrame(name string, env *Environment) {
+	i.frames = append(i.frames, &StackFrame{Name: name, env: env, callEnv: env, globals: i.globals})
+}
+
+func (i *Interpreter) popFrame() {
+	i.frames = i.frames[:len(i.frames)-1]
+}
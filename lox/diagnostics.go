@@ -0,0 +1,208 @@
+package lox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// DiagnosticFormat selects how errors are reported.
+type DiagnosticFormat string
+
+const (
+	// DiagnosticsPretty shows the error kind, where it happened and the
+	// offending source line with the span underlined.
+	DiagnosticsPretty DiagnosticFormat = "pretty"
+
+	// DiagnosticsPlain is the classic one-line "[line N] Error: ..." format.
+	DiagnosticsPlain DiagnosticFormat = "plain"
+
+	// DiagnosticsJSON writes one JSON object per line for each error.
+	DiagnosticsJSON DiagnosticFormat = "json"
+)
+
+const (
+	colorReset  = "\x1b[0m"
+	colorBold   = "\x1b[1m"
+	colorRed    = "\x1b[1;31m"
+	colorYellow = "\x1b[1;33m"
+	colorBlue   = "\x1b[1;34m"
+	colorCyan   = "\x1b[1;36m"
+)
+
+// Diagnostics reports the errors found in a single source file.
+type Diagnostics struct {
+	File   string
+	Source string
+	Format DiagnosticFormat
+
+	// Color enables ANSI colours in the pretty format. It should only be
+	// set when writing to a terminal.
+	Color bool
+}
+
+// Report writes every Lox error carried by err to w. Errors that don't
+// come from Lox are written as they are, or as an object of kind "Error"
+// in the JSON format.
+func (d *Diagnostics) Report(w io.Writer, err error) {
+	var loxErrors Errors
+	if !errors.As(err, &loxErrors) {
+		var loxError Error
+		if !errors.As(err, &loxError) {
+			if d.Format == DiagnosticsJSON {
+				d.writeJSON(w, jsonDiagnostic{Kind: "Error", Message: err.Error(), File: d.File, ExitCode: 1})
+				return
+			}
+
+			fmt.Fprintln(w, err.Error())
+			return
+		}
+		loxErrors = Errors{loxError}
+	}
+
+	for i, loxError := range loxErrors {
+		switch d.Format {
+		case DiagnosticsJSON:
+			d.writeJSON(w, newJSONDiagnostic(d.File, loxError))
+		case DiagnosticsPretty:
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			d.writePretty(w, loxError)
+		default:
+			fmt.Fprintln(w, loxError.Error())
+		}
+	}
+}
+
+// jsonDiagnostic is the shape of an error in the JSON format. Line is 0
+// and Column and Span are left out when the position isn't known.
+type jsonDiagnostic struct {
+	Kind     string   `json:"kind"`
+	Rule     string   `json:"rule,omitempty"`
+	Message  string   `json:"message"`
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	Column   int      `json:"column,omitempty"`
+	Span     *Span    `json:"span,omitempty"`
+	ExitCode int      `json:"exitCode"`
+	Notes    []string `json:"notes,omitempty"`
+}
+
+func newJSONDiagnostic(file string, err Error) jsonDiagnostic {
+	diagnostic := jsonDiagnostic{
+		Kind:     err.Type,
+		Rule:     err.Rule,
+		Message:  err.Message,
+		File:     file,
+		Line:     err.Token.Line,
+		ExitCode: err.ExitCode,
+		Notes:    err.Notes,
+	}
+
+	if span := err.Token.Span; span.Start.Line > 0 {
+		diagnostic.Line = span.Start.Line
+		diagnostic.Column = span.Start.Column
+		diagnostic.Span = &span
+	}
+
+	return diagnostic
+}
+
+func (d *Diagnostics) writeJSON(w io.Writer, diagnostic jsonDiagnostic) {
+	encoded, err := json.Marshal(diagnostic)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Fprintf(w, "%s\n", encoded)
+}
+
+func (d *Diagnostics) writePretty(w io.Writer, err Error) {
+	span := err.Token.Span
+	line := span.Start.Line
+	if line == 0 {
+		line = err.Token.Line
+	}
+
+	kind, color := err.Type, colorRed
+	if err.Type == LintWarning {
+		kind, color = err.Type+"["+err.Rule+"]", colorYellow
+	}
+
+	fmt.Fprintf(w, "%s: %s\n", d.paint(color, kind), d.paint(colorBold, err.Message))
+
+	location := d.File
+	if line > 0 {
+		location += ":" + strconv.Itoa(line)
+		if span.Start.Line > 0 {
+			location += ":" + strconv.Itoa(span.Start.Column)
+		}
+	}
+
+	gutter := strings.Repeat(" ", len(strconv.Itoa(line)))
+	fmt.Fprintf(w, "%s%s %s\n", gutter, d.paint(colorBlue, "-->"), location)
+
+	if text, ok := d.sourceLine(line); ok {
+		fmt.Fprintf(w, "%s %s\n", gutter, d.paint(colorBlue, "|"))
+		fmt.Fprintf(w, "%s %s %s\n", d.paint(colorBlue, strconv.Itoa(line)), d.paint(colorBlue, "|"), text)
+		if span.Start.Line > 0 {
+			fmt.Fprintf(w, "%s %s %s\n", gutter, d.paint(colorBlue, "|"), d.underline(text, span, color))
+		}
+	}
+
+	for _, note := range err.Notes {
+		fmt.Fprintf(w, "%s %s %s\n", gutter, d.paint(colorCyan, "= note:"), note)
+	}
+}
+
+// sourceLine returns the text of the given line without its line break.
+func (d *Diagnostics) sourceLine(line int) (string, bool) {
+	if line < 1 || d.Source == "" {
+		return "", false
+	}
+
+	lines := strings.Split(d.Source, "\n")
+	if line > len(lines) {
+		return "", false
+	}
+
+	return strings.TrimRight(lines[line-1], "\r"), true
+}
+
+// underline marks span below text, the line the span starts on. A span
+// that runs onto later lines is underlined up to the end of this one.
+func (d *Diagnostics) underline(text string, span Span, color string) string {
+	start := min(span.Start.Column-1, len(text))
+
+	end := len(text)
+	if span.End.Line == span.Start.Line {
+		end = min(span.End.Column-1, len(text))
+	}
+
+	var padding strings.Builder
+	for _, r := range text[:start] {
+		if r == '\t' {
+			padding.WriteRune('\t')
+		} else {
+			padding.WriteRune(' ')
+		}
+	}
+
+	width := utf8.RuneCountInString(text[start:max(start, end)])
+	marker := "^" + strings.Repeat("~", max(width-1, 0))
+
+	return padding.String() + d.paint(color, marker)
+}
+
+func (d *Diagnostics) paint(color string, text string) string {
+	if !d.Color {
+		return text
+	}
+
+	return color + text + colorReset
+}
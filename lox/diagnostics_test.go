@@ -0,0 +1,94 @@
+package lox_test
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/michalzarsm/lox-interpreter/lox"
+)
+
+// evalErrors runs the source on a fresh runtime and returns the error it
+// fails with.
+func evalErrors(t *testing.T, source string) error {
+	t.Helper()
+
+	runtime := lox.NewRuntime()
+	runtime.Stdout = io.Discard
+
+	_, err := runtime.Eval(source)
+	if err == nil {
+		t.Fatal("the source runs without an error")
+	}
+
+	return err
+}
+
+// TestPrettyDiagnosticsGolden reports errors in the pretty format and
+// compares them with testdata/diagnostics/<name>.golden. ANSI escapes are
+// written as \e so the files stay readable.
+func TestPrettyDiagnosticsGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		color  bool
+		lint   bool
+	}{
+		{name: "single_column", source: "var a = 1;\nprint a +;\n"},
+		{name: "multi_column", source: "print 1;\nprint missing;\n"},
+		{name: "across_lines", source: "fun f() {\n  return 1;\n  print 2;\n  print 3;\n}\nf();\n", lint: true},
+		{name: "tabs", source: "if (true) {\n\tprint\t\"a\" - 1;\n}\n"},
+		{name: "several_errors", source: "print 1 +;\nvar = 2;\n"},
+		{name: "color_off", source: "fun f() {\n  return missing;\n}\nf();\n"},
+		{name: "color_on", source: "fun f() {\n  return missing;\n}\nf();\n", color: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diagnostics := &lox.Diagnostics{File: "test.lox", Source: test.source, Format: lox.DiagnosticsPretty, Color: test.color}
+
+			var err error
+			if test.lint {
+				warnings, lintErr := lox.Lint(test.source)
+				if lintErr != nil {
+					t.Fatalf("lint: %v", lintErr)
+				}
+				err = warnings
+			} else {
+				err = evalErrors(t, test.source)
+			}
+
+			var report strings.Builder
+			diagnostics.Report(&report, err)
+
+			got := strings.ReplaceAll(report.String(), "\x1b", `\e`)
+			compareGolden(t, filepath.Join("testdata", "diagnostics", test.name+".golden"), got)
+		})
+	}
+}
+
+// TestJSONDiagnosticsGolden pins the shape of the JSON format, which
+// editors consume: lines and columns count from 1, span offsets from 0,
+// runtime errors carry a span like syntax errors do, and notes come last.
+func TestJSONDiagnosticsGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{name: "syntax_error", source: "var a = 1;\nprint a +;\n"},
+		{name: "runtime_error", source: "var a = 1;\nprint a + \"b\";\n"},
+		{name: "notes", source: "print \"\\q\";\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diagnostics := &lox.Diagnostics{File: "test.lox", Source: test.source, Format: lox.DiagnosticsJSON}
+
+			var report strings.Builder
+			diagnostics.Report(&report, evalErrors(t, test.source))
+
+			compareGolden(t, filepath.Join("testdata", "diagnostics", test.name+".jsonl"), report.String())
+		})
+	}
+}
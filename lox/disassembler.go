@@ -0,0 +1,152 @@
+package lox
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+var opCodeNames = map[OpCode]string{
+	OP_CONSTANT:      "OP_CONSTANT",
+	OP_NIL:           "OP_NIL",
+	OP_TRUE:          "OP_TRUE",
+	OP_FALSE:         "OP_FALSE",
+	OP_POP:           "OP_POP",
+	OP_GET_LOCAL:     "OP_GET_LOCAL",
+	OP_SET_LOCAL:     "OP_SET_LOCAL",
+	OP_GET_GLOBAL:    "OP_GET_GLOBAL",
+	OP_DEFINE_GLOBAL: "OP_DEFINE_GLOBAL",
+	OP_SET_GLOBAL:    "OP_SET_GLOBAL",
+	OP_GET_UPVALUE:   "OP_GET_UPVALUE",
+	OP_SET_UPVALUE:   "OP_SET_UPVALUE",
+	OP_GET_PROPERTY:  "OP_GET_PROPERTY",
+	OP_SET_PROPERTY:  "OP_SET_PROPERTY",
+	OP_GET_SUPER:     "OP_GET_SUPER",
+	OP_EQUAL:         "OP_EQUAL",
+	OP_NOT_EQUAL:     "OP_NOT_EQUAL",
+	OP_GREATER:       "OP_GREATER",
+	OP_GREATER_EQUAL: "OP_GREATER_EQUAL",
+	OP_LESS:          "OP_LESS",
+	OP_LESS_EQUAL:    "OP_LESS_EQUAL",
+	OP_ADD:           "OP_ADD",
+	OP_SUBTRACT:      "OP_SUBTRACT",
+	OP_MULTIPLY:      "OP_MULTIPLY",
+	OP_DIVIDE:        "OP_DIVIDE",
+	OP_NOT:           "OP_NOT",
+	OP_NEGATE:        "OP_NEGATE",
+	OP_PRINT:         "OP_PRINT",
+	OP_JUMP:          "OP_JUMP",
+	OP_JUMP_IF_FALSE: "OP_JUMP_IF_FALSE",
+	OP_LOOP:          "OP_LOOP",
+	OP_CALL:          "OP_CALL",
+	OP_CLOSURE:       "OP_CLOSURE",
+	OP_CLOSE_UPVALUE: "OP_CLOSE_UPVALUE",
+	OP_RETURN:        "OP_RETURN",
+	OP_CLASS:         "OP_CLASS",
+	OP_INHERIT:       "OP_INHERIT",
+	OP_METHOD:        "OP_METHOD",
+	OP_BUILD_LIST:    "OP_BUILD_LIST",
+	OP_GET_INDEX:     "OP_GET_INDEX",
+	OP_SET_INDEX:     "OP_SET_INDEX",
+	OP_SLICE:         "OP_SLICE",
+	OP_BUILD_MAP:     "OP_BUILD_MAP",
+	OP_STRINGIFY:     "OP_STRINGIFY",
+}
+
+func (op OpCode) String() string {
+	if name, ok := opCodeNames[op]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("OP_UNKNOWN(%d)", byte(op))
+}
+
+// Disassemble writes a listing of the function's bytecode to w, followed by
+// the listings of every function declared inside it in the order they
+// appear in its constant pool.
+//
+// Each instruction is printed on its own line as its offset, its source
+// line (or "|" when unchanged from the previous instruction), its name and
+// its operands. Constants are shown after their index, with strings quoted
+// so they can't be mistaken for other values.
+func Disassemble(w io.Writer, function *CompiledFunction) {
+	fmt.Fprintf(w, "== %s ==\n", function)
+
+	chunk := function.Chunk
+	for offset := 0; offset < len(chunk.Code); {
+		offset = disassembleInstruction(w, chunk, offset)
+	}
+
+	for _, constant := range chunk.Constants {
+		if nested, ok := constant.(*CompiledFunction); ok {
+			fmt.Fprintln(w)
+			Disassemble(w, nested)
+		}
+	}
+}
+
+func disassembleInstruction(w io.Writer, chunk *Chunk, offset int) int {
+	fmt.Fprintf(w, "%04d ", offset)
+
+	line := chunk.getLine(offset)
+	if offset > 0 && line == chunk.getLine(offset-1) {
+		fmt.Fprint(w, "   | ")
+	} else {
+		fmt.Fprintf(w, "%4d ", line)
+	}
+
+	op := OpCode(chunk.Code[offset])
+
+	switch op {
+	case OP_CONSTANT, OP_GET_GLOBAL, OP_DEFINE_GLOBAL, OP_SET_GLOBAL,
+		OP_GET_PROPERTY, OP_SET_PROPERTY, OP_GET_SUPER, OP_CLASS, OP_METHOD:
+		constant := readShortOperand(chunk, offset+1)
+		fmt.Fprintf(w, "%-16s %4d %s\n", op, constant, formatConstant(chunk.Constants[constant]))
+		return offset + 3
+	case OP_CALL:
+		fmt.Fprintf(w, "%-16s %4d\n", op, chunk.Code[offset+1])
+		return offset + 2
+	case OP_GET_LOCAL, OP_SET_LOCAL, OP_GET_UPVALUE, OP_SET_UPVALUE, OP_BUILD_LIST, OP_BUILD_MAP:
+		fmt.Fprintf(w, "%-16s %4d\n", op, readShortOperand(chunk, offset+1))
+		return offset + 3
+	case OP_JUMP, OP_JUMP_IF_FALSE:
+		jump := readShortOperand(chunk, offset+1)
+		fmt.Fprintf(w, "%-16s %4d -> %d\n", op, offset, offset+3+jump)
+		return offset + 3
+	case OP_LOOP:
+		jump := readShortOperand(chunk, offset+1)
+		fmt.Fprintf(w, "%-16s %4d -> %d\n", op, offset, offset+3-jump)
+		return offset + 3
+	case OP_CLOSURE:
+		constant := readShortOperand(chunk, offset+1)
+		function := chunk.Constants[constant].(*CompiledFunction)
+		fmt.Fprintf(w, "%-16s %4d %s\n", op, constant, formatConstant(function))
+
+		offset += 3
+		for i := 0; i < function.UpvalueCount; i++ {
+			kind := "upvalue"
+			if chunk.Code[offset] == 1 {
+				kind = "local"
+			}
+			fmt.Fprintf(w, "%04d    |                     %s %d\n", offset, kind, readShortOperand(chunk, offset+1))
+			offset += 3
+		}
+
+		return offset
+	}
+
+	fmt.Fprintf(w, "%s\n", op)
+	return offset + 1
+}
+
+func readShortOperand(chunk *Chunk, offset int) int {
+	return int(chunk.Code[offset])<<8 | int(chunk.Code[offset+1])
+}
+
+func formatConstant(value Value) string {
+	if s, ok := value.(string); ok {
+		return strconv.Quote(s)
+	}
+
+	return stringify(value)
+}
@@ -0,0 +1,60 @@
+package lox_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/michalzarsm/lox-interpreter/lox"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata with the current output")
+
+// TestDisassembleGolden compiles every program in testdata/disassemble and
+// compares its listing with the .golden file next to it. Run the test with
+// -update to accept a deliberate change to the format.
+func TestDisassembleGolden(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "disassemble", "*.lox"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no programs in testdata/disassemble")
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			source, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			function, err := lox.Compile(string(source))
+			if err != nil {
+				t.Fatalf("compile: %v", err)
+			}
+
+			var listing bytes.Buffer
+			lox.Disassemble(&listing, function)
+
+			golden := strings.TrimSuffix(path, ".lox") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, listing.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v; run the test with -update to create it", err)
+			}
+			if listing.String() != string(want) {
+				t.Errorf("listing differs from %s\ngot:\n%s\nwant:\n%s", golden, listing.String(), want)
+			}
+		})
+	}
+}
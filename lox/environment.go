@@ -1,4 +1,4 @@
-package main
+package lox
 
 import "errors"
 
@@ -28,7 +28,7 @@ func (e *Environment) get(name Token) (any, error) {
 		return e.enclosing.get(name)
 	}
 
-	return nil, errors.New("Undefinded variable '" + name.Lexeme + "'.")
+	return nil, errors.New("Undefined variable '" + name.Lexeme + "'.")
 }
 
 func (e *Environment) assign(name Token, value any) error {
@@ -43,5 +43,22 @@ func (e *Environment) assign(name Token, value any) error {
 		return e.enclosing.assign(name, value)
 	}
 
-	return errors.New("Undefinded variable '" + name.Lexeme + "'.")
+	return errors.New("Undefined variable '" + name.Lexeme + "'.")
+}
+
+func (e *Environment) ancestor(distance int) *Environment {
+	env := e
+	for i := 0; i < distance; i++ {
+		env = env.enclosing
+	}
+
+	return env
+}
+
+func (e *Environment) getAt(distance int, name string) any {
+	return e.ancestor(distance).values[name]
+}
+
+func (e *Environment) assignAt(distance int, name Token, value any) {
+	e.ancestor(distance).values[name.Lexeme] = value
 }
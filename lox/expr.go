@@ -0,0 +1,301 @@
+package lox
+
+type Expr interface {
+	Accept(visitor ExprVisitor) any
+	Span() Span
+}
+
+type ExprVisitor interface {
+	VisitAssignExpr(assign *Assign) any
+	VisitTernaryExpr(ternary *Ternary) any
+	VisitBinaryExpr(binary *Binary) any
+	VisitCallExpr(call *Call) any
+	VisitGetExpr(get *Get) any
+	VisitGroupingExpr(grouping *Grouping) any
+	VisitIndexExpr(index *Index) any
+	VisitListExpr(list *List) any
+	VisitLiteralExpr(literal *Literal) any
+	VisitLogicalExpr(logical *Logical) any
+	VisitMapExpr(mapExpr *Map) any
+	VisitSetExpr(set *Set) any
+	VisitSetIndexExpr(setindex *SetIndex) any
+	VisitSliceExpr(slice *Slice) any
+	VisitToStringExpr(tostring *ToString) any
+	VisitSuperExpr(super *Super) any
+	VisitThisExpr(this *This) any
+	VisitUnaryExpr(unary *Unary) any
+	VisitVariableExprExpr(variableexpr *VariableExpr) any
+}
+
+type Assign struct {
+	Name Token
+	value Expr
+	span Span
+}
+
+func (thisAssign *Assign) Accept(visitor ExprVisitor) any {
+	return visitor.VisitAssignExpr(thisAssign)
+}
+
+func (thisAssign *Assign) Span() Span {
+	return thisAssign.span
+}
+
+type Ternary struct {
+	Condition Expr
+	TrueExpr Expr
+	FalseExpr Expr
+	span Span
+}
+
+func (thisTernary *Ternary) Accept(visitor ExprVisitor) any {
+	return visitor.VisitTernaryExpr(thisTernary)
+}
+
+func (thisTernary *Ternary) Span() Span {
+	return thisTernary.span
+}
+
+type Binary struct {
+	Left Expr
+	Operator Token
+	Right Expr
+	span Span
+}
+
+func (thisBinary *Binary) Accept(visitor ExprVisitor) any {
+	return visitor.VisitBinaryExpr(thisBinary)
+}
+
+func (thisBinary *Binary) Span() Span {
+	return thisBinary.span
+}
+
+type Call struct {
+	Callee Expr
+	Paren Token
+	Arguments []Expr
+	span Span
+}
+
+func (thisCall *Call) Accept(visitor ExprVisitor) any {
+	return visitor.VisitCallExpr(thisCall)
+}
+
+func (thisCall *Call) Span() Span {
+	return thisCall.span
+}
+
+type Get struct {
+	Object Expr
+	Name Token
+	span Span
+}
+
+func (thisGet *Get) Accept(visitor ExprVisitor) any {
+	return visitor.VisitGetExpr(thisGet)
+}
+
+func (thisGet *Get) Span() Span {
+	return thisGet.span
+}
+
+type Grouping struct {
+	Expression Expr
+	span Span
+}
+
+func (thisGrouping *Grouping) Accept(visitor ExprVisitor) any {
+	return visitor.VisitGroupingExpr(thisGrouping)
+}
+
+func (thisGrouping *Grouping) Span() Span {
+	return thisGrouping.span
+}
+
+type Index struct {
+	Object Expr
+	Bracket Token
+	Index Expr
+	span Span
+}
+
+func (thisIndex *Index) Accept(visitor ExprVisitor) any {
+	return visitor.VisitIndexExpr(thisIndex)
+}
+
+func (thisIndex *Index) Span() Span {
+	return thisIndex.span
+}
+
+type List struct {
+	Bracket Token
+	Elements []Expr
+	span Span
+}
+
+func (thisList *List) Accept(visitor ExprVisitor) any {
+	return visitor.VisitListExpr(thisList)
+}
+
+func (thisList *List) Span() Span {
+	return thisList.span
+}
+
+type Literal struct {
+	Value any
+	span Span
+}
+
+func (thisLiteral *Literal) Accept(visitor ExprVisitor) any {
+	return visitor.VisitLiteralExpr(thisLiteral)
+}
+
+func (thisLiteral *Literal) Span() Span {
+	return thisLiteral.span
+}
+
+type Logical struct {
+	Left Expr
+	Operator Token
+	Right Expr
+	span Span
+}
+
+func (thisLogical *Logical) Accept(visitor ExprVisitor) any {
+	return visitor.VisitLogicalExpr(thisLogical)
+}
+
+func (thisLogical *Logical) Span() Span {
+	return thisLogical.span
+}
+
+type Map struct {
+	Brace Token
+	Keys []Expr
+	Values []Expr
+	span Span
+}
+
+func (thisMap *Map) Accept(visitor ExprVisitor) any {
+	return visitor.VisitMapExpr(thisMap)
+}
+
+func (thisMap *Map) Span() Span {
+	return thisMap.span
+}
+
+type Set struct {
+	Object Expr
+	Name Token
+	Value Expr
+	span Span
+}
+
+func (thisSet *Set) Accept(visitor ExprVisitor) any {
+	return visitor.VisitSetExpr(thisSet)
+}
+
+func (thisSet *Set) Span() Span {
+	return thisSet.span
+}
+
+type SetIndex struct {
+	Object Expr
+	Bracket Token
+	Index Expr
+	Value Expr
+	span Span
+}
+
+func (thisSetIndex *SetIndex) Accept(visitor ExprVisitor) any {
+	return visitor.VisitSetIndexExpr(thisSetIndex)
+}
+
+func (thisSetIndex *SetIndex) Span() Span {
+	return thisSetIndex.span
+}
+
+type Slice struct {
+	Object Expr
+	Bracket Token
+	Start Expr
+	End Expr
+	span Span
+}
+
+func (thisSlice *Slice) Accept(visitor ExprVisitor) any {
+	return visitor.VisitSliceExpr(thisSlice)
+}
+
+func (thisSlice *Slice) Span() Span {
+	return thisSlice.span
+}
+
+type ToString struct {
+	Expression Expr
+	span Span
+}
+
+func (thisToString *ToString) Accept(visitor ExprVisitor) any {
+	return visitor.VisitToStringExpr(thisToString)
+}
+
+func (thisToString *ToString) Span() Span {
+	return thisToString.span
+}
+
+type Super struct {
+	Keyword Token
+	Method Token
+	span Span
+}
+
+func (thisSuper *Super) Accept(visitor ExprVisitor) any {
+	return visitor.VisitSuperExpr(thisSuper)
+}
+
+func (thisSuper *Super) Span() Span {
+	return thisSuper.span
+}
+
+type This struct {
+	Keyword Token
+	span Span
+}
+
+func (thisThis *This) Accept(visitor ExprVisitor) any {
+	return visitor.VisitThisExpr(thisThis)
+}
+
+func (thisThis *This) Span() Span {
+	return thisThis.span
+}
+
+type Unary struct {
+	Operator Token
+	Right Expr
+	span Span
+}
+
+func (thisUnary *Unary) Accept(visitor ExprVisitor) any {
+	return visitor.VisitUnaryExpr(thisUnary)
+}
+
+func (thisUnary *Unary) Span() Span {
+	return thisUnary.span
+}
+
+type VariableExpr struct {
+	Name Token
+	span Span
+}
+
+func (thisVariableExpr *VariableExpr) Accept(visitor ExprVisitor) any {
+	return visitor.VisitVariableExprExpr(thisVariableExpr)
+}
+
+func (thisVariableExpr *VariableExpr) Span() Span {
+	return thisVariableExpr.span
+}
+
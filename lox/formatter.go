@@ -0,0 +1,479 @@
+package lox
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const formatIndent = "  "
+
+// Format parses the source and prints it back in the canonical layout:
+// two-space indentation, one statement per line, opening braces on the
+// line of the statement they belong to, single spaces around binary
+// operators and at most one blank line in a row. Comments are kept; a
+// comment that shares a line with the end of a statement stays after it,
+// any other comment goes on its own line before the next statement.
+func Format(source string) (string, error) {
+	lox := newLox()
+
+	scanner := newScanner(source, lox)
+	scanner.scanTokens()
+	if err := lox.failed(); err != nil {
+		return "", err
+	}
+
+	parser := newParser(scanner.Tokens, lox)
+	statements := parser.parse()
+	if err := lox.failed(); err != nil {
+		return "", err
+	}
+
+	formatter := &Formatter{
+		source:         source,
+		tokens:         scanner.Tokens,
+		comments:       scanner.Comments,
+		interpolations: interpolations(scanner.Tokens),
+	}
+	formatter.statements(statements, len(source))
+
+	return formatter.out.String(), nil
+}
+
+// Formatter prints statements as canonical source. Statements are written
+// straight to out, while expressions are returned as strings.
+type Formatter struct {
+	out      strings.Builder
+	depth    int
+	source   string
+	tokens   []Token
+	comments []Token
+
+	// interpolations maps the offset where each interpolated string starts
+	// to the offset where it ends.
+	interpolations map[int]int
+
+	// lastLine is the source line of whatever was written last, used to
+	// keep a blank line where the source had one. It is 0 at the start of
+	// a block, where blank lines are dropped.
+	lastLine int
+}
+
+// statements writes each statement on its own line, together with the
+// comments before it, and then the comments left before end.
+func (f *Formatter) statements(statements []Stmt, end int) {
+	f.lastLine = 0
+
+	for _, stmt := range statements {
+		f.commentsBefore(stmt.Span().Start.Offset)
+
+		f.startLine(stmt.Span().Start.Line)
+		stmt.Accept(f)
+		f.trailingComment(stmt.Span().End)
+		f.out.WriteString("\n")
+	}
+
+	f.commentsBefore(end)
+}
+
+// startLine indents a new line, after a blank one if the source had a gap
+// between the last thing written and line.
+func (f *Formatter) startLine(line int) {
+	if f.lastLine > 0 && line > f.lastLine+1 {
+		f.out.WriteString("\n")
+	}
+
+	f.out.WriteString(strings.Repeat(formatIndent, f.depth))
+}
+
+// commentsBefore writes the comments that start before offset, each on a
+// line of its own.
+func (f *Formatter) commentsBefore(offset int) {
+	for len(f.comments) > 0 && f.comments[0].Span.Start.Offset < offset {
+		comment := f.comments[0]
+		f.comments = f.comments[1:]
+
+		f.startLine(comment.Span.Start.Line)
+		f.out.WriteString(comment.Lexeme)
+		f.out.WriteString("\n")
+		f.lastLine = comment.Span.Start.Line
+	}
+}
+
+// trailingComment appends the next comment if it starts on the line where
+// the statement ending at end finishes.
+func (f *Formatter) trailingComment(end Position) {
+	f.lastLine = end.Line
+
+	if len(f.comments) > 0 && f.comments[0].Span.Start.Line == end.Line && f.comments[0].Span.Start.Offset >= end.Offset {
+		f.out.WriteString(" ")
+		f.out.WriteString(f.comments[0].Lexeme)
+		f.comments = f.comments[1:]
+	}
+}
+
+// interpolations finds the interpolated strings among tokens. Parts that
+// resume a string after an expression start with its closing '}'.
+func interpolations(tokens []Token) map[int]int {
+	spans := make(map[int]int)
+	starts := make([]int, 0)
+
+	for _, token := range tokens {
+		resumes := strings.HasPrefix(token.Lexeme, "}")
+
+		switch {
+		case token.Type == INTERPOLATION && !resumes:
+			starts = append(starts, token.Span.Start.Offset)
+		case token.Type == STRING && resumes && len(starts) > 0:
+			spans[starts[len(starts)-1]] = token.Span.End.Offset
+			starts = starts[:len(starts)-1]
+		}
+	}
+
+	return spans
+}
+
+// braceAfter returns the first '{' token at or after offset.
+func (f *Formatter) braceAfter(offset int) Token {
+	i := sort.Search(len(f.tokens), func(i int) bool {
+		return f.tokens[i].Span.Start.Offset >= offset
+	})
+
+	for ; i < len(f.tokens); i++ {
+		if f.tokens[i].Type == LEFT_BRACE {
+			return f.tokens[i]
+		}
+	}
+
+	return Token{}
+}
+
+// block writes a braced list of statements. open is the opening brace and
+// end the offset of the closing one; a comment on the line of the opening
+// brace stays there unless it follows the first statement.
+func (f *Formatter) block(statements []Stmt, open Token, end int) {
+	f.out.WriteString("{")
+
+	if len(statements) == 0 && (len(f.comments) == 0 || f.comments[0].Span.Start.Offset >= end) {
+		f.out.WriteString("}")
+		return
+	}
+
+	first := end
+	if len(statements) > 0 {
+		first = statements[0].Span().Start.Offset
+	}
+	if len(f.comments) > 0 && f.comments[0].Span.Start.Offset < first {
+		f.trailingComment(open.Span.End)
+	}
+	f.out.WriteString("\n")
+
+	f.depth += 1
+	f.statements(statements, end)
+	f.depth -= 1
+
+	f.out.WriteString(strings.Repeat(formatIndent, f.depth))
+	f.out.WriteString("}")
+}
+
+// branch writes the body of an if, while or for. A block follows on the
+// same line; any other statement is indented on the next line.
+func (f *Formatter) branch(stmt Stmt) {
+	if _, ok := stmt.(*Block); ok {
+		f.out.WriteString(" ")
+		stmt.Accept(f)
+		return
+	}
+
+	f.out.WriteString("\n")
+	f.depth += 1
+	f.commentsBefore(stmt.Span().Start.Offset)
+	f.lastLine = 0
+	f.startLine(stmt.Span().Start.Line)
+	stmt.Accept(f)
+	f.trailingComment(stmt.Span().End)
+	f.depth -= 1
+}
+
+func (f *Formatter) format(expr Expr) string {
+	return expr.Accept(f).(string)
+}
+
+func (f *Formatter) function(stmt *Function) {
+	params := make([]string, 0, len(stmt.Params))
+	for _, param := range stmt.Params {
+		params = append(params, param.Lexeme)
+	}
+
+	f.out.WriteString(stmt.Name.Lexeme + "(" + strings.Join(params, ", ") + ") ")
+	f.block(stmt.Body, f.braceAfter(stmt.Name.Span.End.Offset), stmt.Span().End.Offset-1)
+}
+
+func (f *Formatter) VisitBlockStmt(stmt *Block) any {
+	f.block(stmt.Statements, f.braceAfter(stmt.Span().Start.Offset), stmt.Span().End.Offset-1)
+	return nil
+}
+
+func (f *Formatter) VisitBreakStmt(stmt *Break) any {
+	f.out.WriteString("break" + f.label(stmt.Label) + ";")
+	return nil
+}
+
+func (f *Formatter) VisitContinueStmt(stmt *Continue) any {
+	f.out.WriteString("continue" + f.label(stmt.Label) + ";")
+	return nil
+}
+
+// label returns the label named by a break or continue, with the space
+// that separates it from the keyword.
+func (f *Formatter) label(label Token) string {
+	if label.Lexeme == "" {
+		return ""
+	}
+
+	return " " + label.Lexeme
+}
+
+func (f *Formatter) VisitClassStmt(stmt *Class) any {
+	f.out.WriteString("class " + stmt.Name.Lexeme + " ")
+	if stmt.Superclass != nil {
+		f.out.WriteString("< " + f.format(stmt.Superclass) + " ")
+	}
+
+	methods := make([]Stmt, 0, len(stmt.Methods))
+	for _, method := range stmt.Methods {
+		methods = append(methods, method)
+	}
+
+	f.block(methods, f.braceAfter(stmt.Name.Span.End.Offset), stmt.Span().End.Offset-1)
+	return nil
+}
+
+func (f *Formatter) VisitExpressionStmt(stmt *Expression) any {
+	f.out.WriteString(f.format(stmt.Expression) + ";")
+	return nil
+}
+
+func (f *Formatter) VisitForStmt(stmt *For) any {
+	if stmt.Label.Lexeme != "" {
+		f.out.WriteString(stmt.Label.Lexeme + ": ")
+	}
+	f.out.WriteString("for (")
+
+	switch initializer := stmt.Initializer.(type) {
+	case nil:
+		f.out.WriteString(";")
+	case *VariableStmt:
+		f.VisitVariableStmtStmt(initializer)
+	case *Expression:
+		f.VisitExpressionStmt(initializer)
+	}
+
+	if stmt.Condition != nil {
+		f.out.WriteString(" " + f.format(stmt.Condition))
+	}
+	f.out.WriteString(";")
+
+	if stmt.Increment != nil {
+		f.out.WriteString(" " + f.format(stmt.Increment))
+	}
+	f.out.WriteString(")")
+
+	f.branch(stmt.Body)
+	return nil
+}
+
+func (f *Formatter) VisitFunctionStmt(stmt *Function) any {
+	// Methods come through here as well, but are declared without the fun
+	// keyword, so their span starts at the name.
+	if stmt.Span().Start != stmt.Name.Span.Start {
+		f.out.WriteString("fun ")
+	}
+
+	f.function(stmt)
+	return nil
+}
+
+func (f *Formatter) VisitIfStmt(stmt *If) any {
+	f.out.WriteString("if (" + f.format(stmt.Condition) + ")")
+	f.branch(stmt.ThenBranch)
+
+	if stmt.ElseBranch == nil {
+		return nil
+	}
+
+	if _, ok := stmt.ThenBranch.(*Block); ok {
+		f.out.WriteString(" else")
+	} else {
+		f.out.WriteString("\n" + strings.Repeat(formatIndent, f.depth) + "else")
+	}
+
+	if elseIf, ok := stmt.ElseBranch.(*If); ok {
+		f.out.WriteString(" ")
+		f.VisitIfStmt(elseIf)
+		return nil
+	}
+
+	f.branch(stmt.ElseBranch)
+	return nil
+}
+
+func (f *Formatter) VisitPrintStmt(stmt *Print) any {
+	f.out.WriteString("print " + f.format(stmt.Expression) + ";")
+	return nil
+}
+
+func (f *Formatter) VisitReturnStmt(stmt *Return) any {
+	if stmt.Value == nil {
+		f.out.WriteString("return;")
+		return nil
+	}
+
+	f.out.WriteString("return " + f.format(stmt.Value) + ";")
+	return nil
+}
+
+func (f *Formatter) VisitVariableStmtStmt(stmt *VariableStmt) any {
+	if stmt.Initializer == nil {
+		f.out.WriteString("var " + stmt.Name.Lexeme + ";")
+		return nil
+	}
+
+	f.out.WriteString("var " + stmt.Name.Lexeme + " = " + f.format(stmt.Initializer) + ";")
+	return nil
+}
+
+func (f *Formatter) VisitWhileStmt(stmt *While) any {
+	if stmt.Label.Lexeme != "" {
+		f.out.WriteString(stmt.Label.Lexeme + ": ")
+	}
+	f.out.WriteString("while (" + f.format(stmt.Condition) + ")")
+	f.branch(stmt.Body)
+	return nil
+}
+
+func (f *Formatter) VisitAssignExpr(expr *Assign) any {
+	return expr.Name.Lexeme + " = " + f.format(expr.value)
+}
+
+func (f *Formatter) VisitTernaryExpr(expr *Ternary) any {
+	return f.format(expr.Condition) + " ? " + f.format(expr.TrueExpr) + " : " + f.format(expr.FalseExpr)
+}
+
+// VisitBinaryExpr writes an interpolated string, which the parser turns
+// into a concatenation, as it appears in the source.
+func (f *Formatter) VisitBinaryExpr(expr *Binary) any {
+	span := expr.Span()
+	if end, ok := f.interpolations[span.Start.Offset]; ok && end == span.End.Offset {
+		return f.source[span.Start.Offset:span.End.Offset]
+	}
+
+	return f.format(expr.Left) + " " + expr.Operator.Lexeme + " " + f.format(expr.Right)
+}
+
+func (f *Formatter) VisitCallExpr(expr *Call) any {
+	arguments := make([]string, 0, len(expr.Arguments))
+	for _, argument := range expr.Arguments {
+		arguments = append(arguments, f.format(argument))
+	}
+
+	return f.format(expr.Callee) + "(" + strings.Join(arguments, ", ") + ")"
+}
+
+func (f *Formatter) VisitGetExpr(expr *Get) any {
+	return f.format(expr.Object) + "." + expr.Name.Lexeme
+}
+
+func (f *Formatter) VisitGroupingExpr(expr *Grouping) any {
+	return "(" + f.format(expr.Expression) + ")"
+}
+
+func (f *Formatter) VisitIndexExpr(expr *Index) any {
+	return f.format(expr.Object) + "[" + f.format(expr.Index) + "]"
+}
+
+func (f *Formatter) VisitListExpr(expr *List) any {
+	elements := make([]string, 0, len(expr.Elements))
+	for _, element := range expr.Elements {
+		elements = append(elements, f.format(element))
+	}
+
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+func (f *Formatter) VisitLiteralExpr(expr *Literal) any {
+	switch value := expr.Value.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return strconv.FormatBool(value)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case string:
+		// Keep the string's escapes or its raw form.
+		return f.source[expr.Span().Start.Offset:expr.Span().End.Offset]
+	}
+
+	return stringify(expr.Value)
+}
+
+func (f *Formatter) VisitLogicalExpr(expr *Logical) any {
+	return f.format(expr.Left) + " " + expr.Operator.Lexeme + " " + f.format(expr.Right)
+}
+
+func (f *Formatter) VisitSetExpr(expr *Set) any {
+	return f.format(expr.Object) + "." + expr.Name.Lexeme + " = " + f.format(expr.Value)
+}
+
+func (f *Formatter) VisitMapExpr(expr *Map) any {
+	entries := make([]string, 0, len(expr.Keys))
+	for i, key := range expr.Keys {
+		entries = append(entries, f.format(key)+": "+f.format(expr.Values[i]))
+	}
+
+	return "{" + strings.Join(entries, ", ") + "}"
+}
+
+func (f *Formatter) VisitSetIndexExpr(expr *SetIndex) any {
+	return f.format(expr.Object) + "[" + f.format(expr.Index) + "] = " + f.format(expr.Value)
+}
+
+func (f *Formatter) VisitSliceExpr(expr *Slice) any {
+	slice := f.format(expr.Object) + "["
+	if expr.Start != nil {
+		slice += f.format(expr.Start)
+	}
+	slice += ":"
+	if expr.End != nil {
+		slice += f.format(expr.End)
+	}
+
+	return slice + "]"
+}
+
+func (f *Formatter) VisitToStringExpr(expr *ToString) any {
+	return "${" + f.format(expr.Expression) + "}"
+}
+
+func (f *Formatter) VisitSuperExpr(expr *Super) any {
+	return "super." + expr.Method.Lexeme
+}
+
+func (f *Formatter) VisitThisExpr(expr *This) any {
+	return "this"
+}
+
+func (f *Formatter) VisitUnaryExpr(expr *Unary) any {
+	right := f.format(expr.Right)
+
+	// Keep stacked minuses apart so "- -1" doesn't read as a decrement.
+	if expr.Operator.Type == MINUS && strings.HasPrefix(right, "-") {
+		return expr.Operator.Lexeme + " " + right
+	}
+
+	return expr.Operator.Lexeme + right
+}
+
+func (f *Formatter) VisitVariableExprExpr(expr *VariableExpr) any {
+	return expr.Name.Lexeme
+}
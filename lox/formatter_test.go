@@ -0,0 +1,61 @@
+package lox_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/michalzarsm/lox-interpreter/lox"
+)
+
+// TestFormatGolden formats every program in testdata/format and compares
+// the result with the .golden file next to it, then checks that
+// formatting the result again leaves it unchanged.
+func TestFormatGolden(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "format", "*.lox"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no programs in testdata/format")
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			source, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			formatted, err := lox.Format(string(source))
+			if err != nil {
+				t.Fatalf("format: %v", err)
+			}
+
+			golden := strings.TrimSuffix(path, ".lox") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, []byte(formatted), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v; run the test with -update to create it", err)
+			}
+			if formatted != string(want) {
+				t.Errorf("formatted source differs from %s\ngot:\n%s\nwant:\n%s", golden, formatted, want)
+			}
+
+			again, err := lox.Format(formatted)
+			if err != nil {
+				t.Fatalf("format the formatted source: %v", err)
+			}
+			if again != formatted {
+				t.Errorf("formatting twice changes the source\nonce:\n%s\ntwice:\n%s", formatted, again)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package lox
+
+// LoxCallable is implemented by every value that can be called. paren is
+// the closing parenthesis of the call expression and is where errors
+// raised by the call are reported.
+type LoxCallable interface {
+	arity() int
+	call(interpreter *Interpreter, paren Token, arguments []any) any
+}
+
+// returnValue is produced by a return statement and carried up through
+// enclosing blocks and loops until the function call that owns it.
+type returnValue struct {
+	value any
+}
+
+type LoxFunction struct {
+	declaration   *Function
+	closure       *Environment
+	isInitializer bool
+}
+
+func newLoxFunction(declaration *Function, closure *Environment, isInitializer bool) *LoxFunction {
+	return &LoxFunction{
+		declaration:   declaration,
+		closure:       closure,
+		isInitializer: isInitializer,
+	}
+}
+
+// bind returns a copy of the method whose closure has "this" set to the
+// given instance.
+func (f *LoxFunction) bind(instance *LoxInstance) *LoxFunction {
+	env := newEnvironment(f.closure)
+	env.define("this", instance)
+	return newLoxFunction(f.declaration, env, f.isInitializer)
+}
+
+func (f *LoxFunction) arity() int {
+	return len(f.declaration.Params)
+}
+
+func (f *LoxFunction) call(interpreter *Interpreter, paren Token, arguments []any) any {
+	// The VM gives the script the first of its framesMax frames, so stop
+	// one call short of it to report the overflow at the same depth.
+	if interpreter.depth+1 == framesMax {
+		panic(newRuntimeError(paren, "Stack overflow."))
+	}
+
+	interpreter.depth += 1
+	defer func() { interpreter.depth -= 1 }()
+
+	env := newEnvironment(f.closure)
+	for i, param := range f.declaration.Params {
+		env.define(param.Lexeme, arguments[i])
+	}
+
+	if interpreter.debugger != nil {
+		interpreter.pushFrame(f.declaration.Name.Lexeme, env)
+		defer interpreter.popFrame()
+	}
+
+	result := interpreter.executeBlock(f.declaration.Body, env)
+
+	if f.isInitializer {
+		return f.closure.getAt(0, "this")
+	}
+
+	if ret, ok := result.(returnValue); ok {
+		return ret.value
+	}
+
+	return nil
+}
+
+func (f *LoxFunction) String() string {
+	return "<fn " + f.declaration.Name.Lexeme + ">"
+}
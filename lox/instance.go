@@ -0,0 +1,37 @@
+package lox
+
+import "errors"
+
+type LoxInstance struct {
+	class  *LoxClass
+	fields map[string]any
+}
+
+func newLoxInstance(class *LoxClass) *LoxInstance {
+	return &LoxInstance{
+		class:  class,
+		fields: make(map[string]any),
+	}
+}
+
+func (i *LoxInstance) get(name Token) (any, error) {
+	value, ok := i.fields[name.Lexeme]
+	if ok {
+		return value, nil
+	}
+
+	method := i.class.findMethod(name.Lexeme)
+	if method != nil {
+		return method.bind(i), nil
+	}
+
+	return nil, errors.New("Undefined property '" + name.Lexeme + "'.")
+}
+
+func (i *LoxInstance) set(name Token, value any) {
+	i.fields[name.Lexeme] = value
+}
+
+func (i *LoxInstance) String() string {
+	return i.class.name + " instance"
+}
@@ -0,0 +1,702 @@
+package lox
+
+import (
+	"fmt"
+	"io"
+)
+
+type Interpreter struct {
+	env     *Environment
+	globals *Environment
+	locals  map[Expr]int
+	stdout  io.Writer
+
+	// debugger, if set, is stepped before every statement, and frames
+	// then tracks the calls in progress for it.
+	debugger Debugger
+	frames   []*StackFrame
+
+	// depth counts the Lox function calls in progress, so that runaway
+	// recursion becomes a runtime error rather than a Go stack overflow.
+	depth int
+}
+
+func newInterpreter(env *Environment, stdout io.Writer) *Interpreter {
+	return &Interpreter{
+		env:     env,
+		globals: env,
+		locals:  make(map[Expr]int),
+		stdout:  stdout,
+	}
+}
+
+// interpret runs the statements and returns the first runtime error, if
+// any. Visitors report runtime errors by panicking with an Error built by
+// newRuntimeError so they don't have to be checked after every evaluate;
+// they are recovered here and turned back into a plain return value.
+func (i *Interpreter) interpret(statements []Stmt) error {
+	_, err := i.interpretValue(statements)
+	return err
+}
+
+// interpretValue works like interpret but also returns the value of the
+// last statement when it is an expression statement.
+func (i *Interpreter) interpretValue(statements []Stmt) (value any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			runtimeError, ok := r.(Error)
+			if !ok || runtimeError.Type != RuntimeError {
+				panic(r)
+			}
+
+			i.env = i.globals
+			value = nil
+			err = runtimeError
+		}
+	}()
+
+	if i.debugger != nil {
+		i.frames = i.frames[:0]
+		i.pushFrame("<script>", nil)
+	}
+
+	for _, statement := range statements {
+		if expression, ok := statement.(*Expression); ok {
+			if i.debugger != nil {
+				i.step(statement)
+			}
+
+			value = i.evaluate(expression.Expression)
+			continue
+		}
+
+		value = nil
+		i.execute(statement)
+	}
+
+	return value, nil
+}
+
+// execute runs a single statement. A non-nil result is a control flow
+// signal, such as a returnValue, that has to be passed up to the caller.
+func (i *Interpreter) execute(stmt Stmt) any {
+	if i.debugger != nil {
+		i.step(stmt)
+	}
+
+	return stmt.Accept(i)
+}
+
+func (i *Interpreter) executeBlock(statements []Stmt, env *Environment) any {
+	previous := i.env
+	i.env = env
+	defer func() {
+		i.env = previous
+	}()
+
+	for _, statement := range statements {
+		result := i.execute(statement)
+		if result != nil {
+			return result
+		}
+	}
+
+	return nil
+}
+
+// resolve records how many scopes lie between the expression and the
+// scope that declares the variable it refers to.
+func (i *Interpreter) resolve(expr Expr, depth int) {
+	i.locals[expr] = depth
+}
+
+func (i *Interpreter) VisitBlockStmt(stmt *Block) any {
+	return i.executeBlock(stmt.Statements, newEnvironment(i.env))
+}
+
+func (i *Interpreter) VisitLiteralExpr(literal *Literal) any {
+	return literal.Value
+}
+
+func (i *Interpreter) VisitGroupingExpr(grouping *Grouping) any {
+	return i.evaluate(grouping.Expression)
+}
+
+func (i *Interpreter) evaluate(expr Expr) any {
+	return expr.Accept(i)
+}
+
+func (i *Interpreter) VisitUnaryExpr(unary *Unary) any {
+	right := i.evaluate(unary.Right)
+
+	switch unary.Operator.Type {
+	case BANG:
+		{
+			return !isTruthy(right)
+		}
+	case MINUS:
+		{
+			ok := checkNumberOperand(right)
+			if ok {
+				return -right.(float64)
+			}
+
+			panic(newRuntimeError(unary.Operator, "Operand must be a number."))
+		}
+	}
+
+	return nil
+}
+
+func (i *Interpreter) VisitVariableExprExpr(expr *VariableExpr) any {
+	return i.lookUpVariable(expr.Name, expr)
+}
+
+func (i *Interpreter) lookUpVariable(name Token, expr Expr) any {
+	distance, ok := i.locals[expr]
+	if ok {
+		return i.env.getAt(distance, name.Lexeme)
+	}
+
+	getVar, err := i.globals.get(name)
+	if err != nil {
+		panic(newRuntimeError(name, err.Error()))
+	}
+
+	return getVar
+}
+
+func (i *Interpreter) VisitAssignExpr(expr *Assign) any {
+	value := i.evaluate(expr.value)
+
+	distance, ok := i.locals[expr]
+	if ok {
+		i.env.assignAt(distance, expr.Name, value)
+		return value
+	}
+
+	err := i.globals.assign(expr.Name, value)
+	if err != nil {
+		panic(newRuntimeError(expr.Name, err.Error()))
+	}
+
+	return value
+}
+
+func (i *Interpreter) VisitBinaryExpr(binary *Binary) any {
+	left := i.evaluate(binary.Left)
+	right := i.evaluate(binary.Right)
+
+	switch binary.Operator.Type {
+	case GREATER:
+		{
+			ok := checkNumberOperands(left, right)
+			if ok {
+				return left.(float64) > right.(float64)
+			}
+
+			panic(newRuntimeError(binary.Operator, "Operands must be numbers."))
+		}
+	case GREATER_EQUAL:
+		{
+			ok := checkNumberOperands(left, right)
+			if ok {
+				return left.(float64) >= right.(float64)
+			}
+
+			panic(newRuntimeError(binary.Operator, "Operands must be numbers."))
+		}
+	case LESS:
+		{
+			ok := checkNumberOperands(left, right)
+			if ok {
+				return left.(float64) < right.(float64)
+			}
+
+			panic(newRuntimeError(binary.Operator, "Operands must be numbers."))
+		}
+	case LESS_EQUAL:
+		{
+			ok := checkNumberOperands(left, right)
+			if ok {
+				return left.(float64) <= right.(float64)
+			}
+
+			panic(newRuntimeError(binary.Operator, "Operands must be numbers."))
+		}
+	case BANG_EQUAL:
+		{
+			return !isEqual(left, right)
+		}
+	case EQUAL_EQUAL:
+		{
+			return isEqual(left, right)
+		}
+	case MINUS:
+		{
+			ok := checkNumberOperands(left, right)
+			if ok {
+				return left.(float64) - right.(float64)
+			}
+
+			panic(newRuntimeError(binary.Operator, "Operands must be numbers."))
+		}
+	case PLUS:
+		{
+			okNumber := checkNumberOperands(left, right)
+			if okNumber {
+				return left.(float64) + right.(float64)
+			}
+
+			okString := checkStringOperands(left, right)
+			if okString {
+				return left.(string) + right.(string)
+			}
+
+			panic(newRuntimeError(binary.Operator, "Operands must be two numbers or two strings."))
+		}
+	case SLASH:
+		{
+			ok := checkNumberOperands(left, right)
+			if ok {
+				return left.(float64) / right.(float64)
+			}
+
+			panic(newRuntimeError(binary.Operator, "Operands must be numbers."))
+		}
+	case STAR:
+		{
+			ok := checkNumberOperands(left, right)
+			if ok {
+				return left.(float64) * right.(float64)
+			}
+
+			panic(newRuntimeError(binary.Operator, "Operands must be numbers."))
+		}
+	}
+
+	return nil
+}
+
+func (i *Interpreter) VisitCallExpr(expr *Call) any {
+	callee := i.evaluate(expr.Callee)
+
+	arguments := make([]any, 0, len(expr.Arguments))
+	for _, argument := range expr.Arguments {
+		arguments = append(arguments, i.evaluate(argument))
+	}
+
+	function, ok := callee.(LoxCallable)
+	if !ok {
+		panic(newRuntimeError(expr.Paren, "Can only call functions and classes."))
+	}
+
+	if len(arguments) != function.arity() {
+		panic(newRuntimeError(expr.Paren, fmt.Sprintf("Expected %d arguments but got %d.", function.arity(), len(arguments))))
+	}
+
+	return function.call(i, expr.Paren, arguments)
+}
+
+func (i *Interpreter) VisitGetExpr(expr *Get) any {
+	object := i.evaluate(expr.Object)
+
+	var value any
+	var err error
+
+	switch object := object.(type) {
+	case *LoxInstance:
+		value, err = object.get(expr.Name)
+	case *LoxList:
+		value, err = object.get(expr.Name)
+	case *LoxMap:
+		value, err = object.get(expr.Name)
+	default:
+		panic(newRuntimeError(expr.Name, "Only instances have properties."))
+	}
+
+	if err != nil {
+		panic(newRuntimeError(expr.Name, err.Error()))
+	}
+
+	return value
+}
+
+func (i *Interpreter) VisitListExpr(expr *List) any {
+	elements := make([]any, 0, len(expr.Elements))
+	for _, element := range expr.Elements {
+		elements = append(elements, i.evaluate(element))
+	}
+
+	return newLoxList(elements)
+}
+
+func (i *Interpreter) VisitMapExpr(expr *Map) any {
+	// Every entry is evaluated before any is set, the way the VM builds a
+	// map from the stack, so a bad key fails after the same side effects.
+	entries := make([]Value, 0, 2*len(expr.Keys))
+	for index, key := range expr.Keys {
+		entries = append(entries, i.evaluate(key), i.evaluate(expr.Values[index]))
+	}
+
+	result := newLoxMap()
+	for index := 0; index < len(entries); index += 2 {
+		if err := result.set(entries[index], entries[index+1]); err != nil {
+			panic(newRuntimeError(expr.Brace, err.Error()))
+		}
+	}
+
+	return result
+}
+
+func (i *Interpreter) VisitIndexExpr(expr *Index) any {
+	object := i.evaluate(expr.Object)
+	index := i.evaluate(expr.Index)
+
+	list, ok := object.(*LoxList)
+	if !ok {
+		panic(newRuntimeError(expr.Bracket, "Only lists can be indexed."))
+	}
+
+	value, err := list.getIndex(index)
+	if err != nil {
+		panic(newRuntimeError(expr.Bracket, err.Error()))
+	}
+
+	return value
+}
+
+func (i *Interpreter) VisitSetIndexExpr(expr *SetIndex) any {
+	object := i.evaluate(expr.Object)
+	index := i.evaluate(expr.Index)
+	value := i.evaluate(expr.Value)
+
+	list, ok := object.(*LoxList)
+	if !ok {
+		panic(newRuntimeError(expr.Bracket, "Only lists can be indexed."))
+	}
+
+	if err := list.setIndex(index, value); err != nil {
+		panic(newRuntimeError(expr.Bracket, err.Error()))
+	}
+
+	return value
+}
+
+func (i *Interpreter) VisitSliceExpr(expr *Slice) any {
+	object := i.evaluate(expr.Object)
+
+	var start, end any
+	if expr.Start != nil {
+		start = i.evaluate(expr.Start)
+	}
+	if expr.End != nil {
+		end = i.evaluate(expr.End)
+	}
+
+	list, ok := object.(*LoxList)
+	if !ok {
+		panic(newRuntimeError(expr.Bracket, "Only lists can be sliced."))
+	}
+
+	slice, err := list.slice(start, end)
+	if err != nil {
+		panic(newRuntimeError(expr.Bracket, err.Error()))
+	}
+
+	return slice
+}
+
+func (i *Interpreter) VisitSetExpr(expr *Set) any {
+	object := i.evaluate(expr.Object)
+
+	// The value is evaluated before the object is checked, matching the
+	// order in which the VM has both on its stack.
+	value := i.evaluate(expr.Value)
+
+	instance, ok := object.(*LoxInstance)
+	if !ok {
+		panic(newRuntimeError(expr.Name, "Only instances have fields."))
+	}
+
+	instance.set(expr.Name, value)
+
+	return value
+}
+
+func (i *Interpreter) VisitToStringExpr(expr *ToString) any {
+	return stringify(i.evaluate(expr.Expression))
+}
+
+func (i *Interpreter) VisitSuperExpr(expr *Super) any {
+	distance := i.locals[expr]
+	superclass := i.env.getAt(distance, "super")
+
+	// The environment binding "this" is always right inside the one
+	// binding "super".
+	object := i.env.getAt(distance-1, "this")
+
+	method := superclass.(*LoxClass).findMethod(expr.Method.Lexeme)
+	if method == nil {
+		panic(newRuntimeError(expr.Method, fmt.Sprintf("Undefined property '%s'.", expr.Method.Lexeme)))
+	}
+
+	return method.bind(object.(*LoxInstance))
+}
+
+func (i *Interpreter) VisitThisExpr(expr *This) any {
+	return i.lookUpVariable(expr.Keyword, expr)
+}
+
+func (i *Interpreter) VisitLogicalExpr(expr *Logical) any {
+	left := i.evaluate(expr.Left)
+
+	if expr.Operator.Type == OR {
+		if isTruthy(left) {
+			return left
+		}
+	} else {
+		if !isTruthy(left) {
+			return left
+		}
+	}
+
+	return i.evaluate(expr.Right)
+}
+
+func (i *Interpreter) VisitTernaryExpr(ternary *Ternary) any {
+	if isTruthy(i.evaluate(ternary.Condition)) {
+		return i.evaluate(ternary.TrueExpr)
+	}
+
+	return i.evaluate(ternary.FalseExpr)
+}
+
+func (i *Interpreter) VisitClassStmt(stmt *Class) any {
+	var superclass *LoxClass
+	if stmt.Superclass != nil {
+		value := i.evaluate(stmt.Superclass)
+
+		class, ok := value.(*LoxClass)
+		if !ok {
+			panic(newRuntimeError(stmt.Superclass.(*VariableExpr).Name, "Superclass must be a class."))
+		}
+		superclass = class
+	}
+
+	i.env.define(stmt.Name.Lexeme, nil)
+
+	if superclass != nil {
+		i.env = newEnvironment(i.env)
+		i.env.define("super", superclass)
+	}
+
+	methods := make(map[string]*LoxFunction)
+	for _, method := range stmt.Methods {
+		function := newLoxFunction(method, i.env, method.Name.Lexeme == "init")
+		methods[method.Name.Lexeme] = function
+	}
+
+	class := newLoxClass(stmt.Name.Lexeme, superclass, methods)
+
+	if superclass != nil {
+		i.env = i.env.enclosing
+	}
+
+	i.env.assign(stmt.Name, class)
+
+	return nil
+}
+
+func (i *Interpreter) VisitExpressionStmt(stmt *Expression) any {
+	i.evaluate(stmt.Expression)
+
+	return nil
+}
+
+func (i *Interpreter) VisitFunctionStmt(stmt *Function) any {
+	function := newLoxFunction(stmt, i.env, false)
+	i.env.define(stmt.Name.Lexeme, function)
+
+	return nil
+}
+
+func (i *Interpreter) VisitIfStmt(stmt *If) any {
+	if isTruthy(i.evaluate(stmt.Condition)) {
+		return i.execute(stmt.ThenBranch)
+	} else if stmt.ElseBranch != nil {
+		return i.execute(stmt.ElseBranch)
+	}
+
+	return nil
+}
+
+func (i *Interpreter) VisitPrintStmt(stmt *Print) any {
+	value := i.evaluate(stmt.Expression)
+	fmt.Fprintln(i.stdout, stringify(value))
+
+	return nil
+}
+
+func (i *Interpreter) VisitReturnStmt(stmt *Return) any {
+	var value any
+	if stmt.Value != nil {
+		value = i.evaluate(stmt.Value)
+	}
+
+	return returnValue{value}
+}
+
+func (i *Interpreter) VisitVariableStmtStmt(stmt *VariableStmt) any {
+	var value any
+	if stmt.Initializer != nil {
+		value = i.evaluate(stmt.Initializer)
+	}
+
+	i.env.define(stmt.Name.Lexeme, value)
+
+	return nil
+}
+
+// breakLoop and continueLoop are produced by break and continue
+// statements and carried up through execute, like a returnValue, until
+// they reach the loop they name. An empty label names the innermost loop.
+type breakLoop struct {
+	label string
+}
+
+type continueLoop struct {
+	label string
+}
+
+// loopControl decides what a loop does after its body produced result:
+// go on with the next iteration, or stop and pass the result, if any, on
+// to the statements around the loop.
+func loopControl(label Token, result any) (next bool, passOn any) {
+	switch signal := result.(type) {
+	case nil:
+		return true, nil
+	case breakLoop:
+		if signal.label == "" || signal.label == label.Lexeme {
+			return false, nil
+		}
+	case continueLoop:
+		if signal.label == "" || signal.label == label.Lexeme {
+			return true, nil
+		}
+	}
+
+	return false, result
+}
+
+func (i *Interpreter) VisitBreakStmt(stmt *Break) any {
+	return breakLoop{stmt.Label.Lexeme}
+}
+
+func (i *Interpreter) VisitContinueStmt(stmt *Continue) any {
+	return continueLoop{stmt.Label.Lexeme}
+}
+
+// VisitForStmt runs the loop in a scope of its own, which holds the
+// variable declared by the initializer.
+func (i *Interpreter) VisitForStmt(stmt *For) any {
+	previous := i.env
+	i.env = newEnvironment(i.env)
+	defer func() {
+		i.env = previous
+	}()
+
+	if stmt.Initializer != nil {
+		i.execute(stmt.Initializer)
+	}
+
+	for stmt.Condition == nil || isTruthy(i.evaluate(stmt.Condition)) {
+		next, result := loopControl(stmt.Label, i.execute(stmt.Body))
+		if !next {
+			return result
+		}
+
+		if stmt.Increment != nil {
+			i.evaluate(stmt.Increment)
+		}
+	}
+
+	return nil
+}
+
+func (i *Interpreter) VisitWhileStmt(stmt *While) any {
+	for isTruthy(i.evaluate(stmt.Condition)) {
+		next, result := loopControl(stmt.Label, i.execute(stmt.Body))
+		if !next {
+			return result
+		}
+	}
+
+	return nil
+}
+
+func stringify(val any) string {
+	if val == nil {
+		return "nil"
+	}
+
+	return fmt.Sprint(val)
+}
+
+func isTruthy(val any) bool {
+	if val == nil {
+		return false
+	}
+
+	valBool, ok := val.(bool)
+
+	if ok {
+		return valBool
+	}
+
+	return true
+}
+
+func isEqual(a any, b any) bool {
+	if a == nil && b == nil {
+		return true
+	}
+	if a == nil {
+		return false
+	}
+
+	return a == b
+}
+
+func checkNumberOperand(operand any) bool {
+	_, ok := operand.(float64)
+
+	return ok
+}
+
+func checkNumberOperands(operands ...any) bool {
+	for _, operand := range operands {
+		ok := checkNumberOperand(operand)
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+func checkStringOperand(operand any) bool {
+	_, ok := operand.(string)
+	return ok
+}
+
+func checkStringOperands(operands ...any) bool {
+	for _, operand := range operands {
+		ok := checkStringOperand(operand)
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,438 @@
+package lox
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type LintRule = string
+
+const (
+	LintUnusedVariable     LintRule = "unused-variable"
+	LintShadowedVariable   LintRule = "shadowed-variable"
+	LintUnreachableCode    LintRule = "unreachable-code"
+	LintSelfAssignment     LintRule = "self-assignment"
+	LintNilComparison      LintRule = "nil-comparison"
+	LintNumberStringConcat LintRule = "number-string-concat"
+)
+
+// Comment directives that turn lint rules off. Each is followed by the
+// rule IDs it applies to, separated by commas or spaces, or by nothing to
+// turn off every rule.
+const (
+	lintDisableFile     = "lint-disable"
+	lintDisableLine     = "lint-disable-line"
+	lintDisableNextLine = "lint-disable-next-line"
+)
+
+// Lint looks for code that is legal but most likely a mistake. It returns
+// an error instead if the program doesn't compile, and otherwise one
+// warning per finding, in source order, leaving out the rules that the
+// source turns off through comment directives:
+//
+//	// lint-disable unused-variable           for the whole file
+//	var x = 1; // lint-disable-line           for this line
+//	// lint-disable-next-line self-assignment for the line below
+func Lint(source string) (Errors, error) {
+	analysis := Analyze(source)
+	if len(analysis.Errors) > 0 {
+		return nil, append(Errors(nil), analysis.Errors...)
+	}
+
+	linter := newLinter(analysis.Comments)
+	linter.symbols(analysis.Symbols)
+	linter.statements(analysis.Statements)
+
+	sort.SliceStable(linter.warnings, func(i, j int) bool {
+		return linter.warnings[i].Token.Span.Start.Offset < linter.warnings[j].Token.Span.Start.Offset
+	})
+
+	return linter.warnings, nil
+}
+
+// Linter walks the AST and collects warnings. Rules about names work from
+// the symbols found by the Resolver; the others look at the statements
+// and expressions themselves.
+type Linter struct {
+	warnings Errors
+
+	// disabled holds the rules turned off for the whole file, under line
+	// 0, and for single lines. An empty rule stands for all of them.
+	disabled map[int]map[LintRule]bool
+}
+
+func newLinter(comments []Token) *Linter {
+	linter := &Linter{
+		warnings: make(Errors, 0),
+		disabled: make(map[int]map[LintRule]bool),
+	}
+
+	for _, comment := range comments {
+		linter.directive(comment)
+	}
+
+	return linter
+}
+
+// directive records the rules a comment turns off, if it is a directive.
+func (l *Linter) directive(comment Token) {
+	fields := strings.Fields(strings.ReplaceAll(strings.TrimPrefix(comment.Lexeme, "//"), ",", " "))
+	if len(fields) == 0 {
+		return
+	}
+
+	line := 0
+	switch fields[0] {
+	case lintDisableFile:
+	case lintDisableLine:
+		line = comment.Line
+	case lintDisableNextLine:
+		line = comment.Line + 1
+	default:
+		return
+	}
+
+	if l.disabled[line] == nil {
+		l.disabled[line] = make(map[LintRule]bool)
+	}
+
+	rules := fields[1:]
+	if len(rules) == 0 {
+		rules = []string{""}
+	}
+
+	for _, rule := range rules {
+		l.disabled[line][rule] = true
+	}
+}
+
+func (l *Linter) isDisabled(rule LintRule, line int) bool {
+	for _, at := range []int{0, line} {
+		if l.disabled[at][rule] || l.disabled[at][""] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l *Linter) warn(rule LintRule, token Token, message string) {
+	if l.isDisabled(rule, token.Line) {
+		return
+	}
+
+	l.warnings = append(l.warnings, Error{Type: LintWarning, Token: token, Message: message, ExitCode: 1, Rule: rule})
+}
+
+// symbols checks the rules about declared names.
+func (l *Linter) symbols(symbols []*Symbol) {
+	for _, symbol := range symbols {
+		if symbol.Kind == SymbolVariable && len(symbol.References) == 0 {
+			l.warn(LintUnusedVariable, symbol.Name, fmt.Sprintf("Variable '%s' is never used.", symbol.Name.Lexeme))
+		}
+
+		if shadowed := symbol.Shadows; shadowed != nil {
+			l.warn(LintShadowedVariable, symbol.Name, fmt.Sprintf("'%s' shadows the %s declared on line %d.", symbol.Name.Lexeme, shadowed.Kind, shadowed.Name.Line))
+		}
+	}
+}
+
+// statements lints a list of statements that run one after another and
+// flags whatever follows a return, break or continue among them.
+func (l *Linter) statements(statements []Stmt) {
+	reported := false
+	for i, stmt := range statements {
+		stmt.Accept(l)
+
+		if i+1 == len(statements) || reported {
+			continue
+		}
+
+		var keyword Token
+		switch stmt := stmt.(type) {
+		case *Return:
+			keyword = stmt.Keyword
+		case *Break:
+			keyword = stmt.Keyword
+		case *Continue:
+			keyword = stmt.Keyword
+		default:
+			continue
+		}
+
+		unreachable := statements[i+1].Span().Through(statements[len(statements)-1].Span())
+		l.warn(LintUnreachableCode, Token{Line: unreachable.Start.Line, Span: unreachable}, "Unreachable code after "+keyword.Lexeme+".")
+		reported = true
+	}
+}
+
+func (l *Linter) lintStmt(stmt Stmt) {
+	if stmt != nil {
+		stmt.Accept(l)
+	}
+}
+
+func (l *Linter) lintExpr(expr Expr) {
+	if expr != nil {
+		expr.Accept(l)
+	}
+}
+
+// constant returns the literal an expression consists of, looking through
+// parentheses.
+func constant(expr Expr) (*Literal, bool) {
+	for {
+		switch e := expr.(type) {
+		case *Grouping:
+			expr = e.Expression
+		case *Literal:
+			return e, true
+		default:
+			return nil, false
+		}
+	}
+}
+
+// sameTarget reports whether two expressions both name the same variable,
+// both are this, or both are the same field of the same target.
+func sameTarget(a Expr, b Expr) bool {
+	switch a := a.(type) {
+	case *VariableExpr:
+		b, ok := b.(*VariableExpr)
+		return ok && a.Name.Lexeme == b.Name.Lexeme
+	case *This:
+		_, ok := b.(*This)
+		return ok
+	case *Get:
+		b, ok := b.(*Get)
+		return ok && a.Name.Lexeme == b.Name.Lexeme && sameTarget(a.Object, b.Object)
+	}
+
+	return false
+}
+
+func (l *Linter) VisitBlockStmt(stmt *Block) any {
+	l.statements(stmt.Statements)
+	return nil
+}
+
+func (l *Linter) VisitBreakStmt(stmt *Break) any {
+	return nil
+}
+
+func (l *Linter) VisitContinueStmt(stmt *Continue) any {
+	return nil
+}
+
+func (l *Linter) VisitClassStmt(stmt *Class) any {
+	for _, method := range stmt.Methods {
+		l.VisitFunctionStmt(method)
+	}
+
+	return nil
+}
+
+func (l *Linter) VisitExpressionStmt(stmt *Expression) any {
+	l.lintExpr(stmt.Expression)
+	return nil
+}
+
+func (l *Linter) VisitForStmt(stmt *For) any {
+	l.lintStmt(stmt.Initializer)
+	l.lintExpr(stmt.Condition)
+	l.lintExpr(stmt.Increment)
+	l.lintStmt(stmt.Body)
+
+	return nil
+}
+
+func (l *Linter) VisitFunctionStmt(stmt *Function) any {
+	l.statements(stmt.Body)
+	return nil
+}
+
+func (l *Linter) VisitIfStmt(stmt *If) any {
+	l.lintExpr(stmt.Condition)
+	l.lintStmt(stmt.ThenBranch)
+	l.lintStmt(stmt.ElseBranch)
+
+	return nil
+}
+
+func (l *Linter) VisitPrintStmt(stmt *Print) any {
+	l.lintExpr(stmt.Expression)
+	return nil
+}
+
+func (l *Linter) VisitReturnStmt(stmt *Return) any {
+	l.lintExpr(stmt.Value)
+	return nil
+}
+
+func (l *Linter) VisitVariableStmtStmt(stmt *VariableStmt) any {
+	l.lintExpr(stmt.Initializer)
+	return nil
+}
+
+func (l *Linter) VisitWhileStmt(stmt *While) any {
+	l.lintExpr(stmt.Condition)
+	l.lintStmt(stmt.Body)
+
+	return nil
+}
+
+func (l *Linter) VisitAssignExpr(expr *Assign) any {
+	if variable, ok := expr.value.(*VariableExpr); ok && variable.Name.Lexeme == expr.Name.Lexeme {
+		l.warn(LintSelfAssignment, expr.Name, fmt.Sprintf("Variable '%s' is assigned to itself.", expr.Name.Lexeme))
+	}
+
+	l.lintExpr(expr.value)
+	return nil
+}
+
+func (l *Linter) VisitTernaryExpr(expr *Ternary) any {
+	l.lintExpr(expr.Condition)
+	l.lintExpr(expr.TrueExpr)
+	l.lintExpr(expr.FalseExpr)
+
+	return nil
+}
+
+func (l *Linter) VisitBinaryExpr(expr *Binary) any {
+	left, leftConstant := constant(expr.Left)
+	right, rightConstant := constant(expr.Right)
+
+	// The operator token, underlining the whole comparison or addition.
+	token := expr.Operator
+	token.Span = expr.Span()
+
+	switch expr.Operator.Type {
+	case EQUAL_EQUAL, BANG_EQUAL:
+		if leftConstant && rightConstant && (left.Value == nil || right.Value == nil) {
+			equal := left.Value == nil && right.Value == nil
+			if expr.Operator.Type == BANG_EQUAL {
+				equal = !equal
+			}
+
+			l.warn(LintNilComparison, token, fmt.Sprintf("Comparison with nil is always %t.", equal))
+		}
+	case PLUS:
+		if leftConstant && rightConstant {
+			_, leftNumber := left.Value.(float64)
+			_, leftString := left.Value.(string)
+			_, rightNumber := right.Value.(float64)
+			_, rightString := right.Value.(string)
+
+			if (leftNumber && rightString) || (leftString && rightNumber) {
+				l.warn(LintNumberStringConcat, token, "Adding a number and a string fails at runtime.")
+			}
+		}
+	}
+
+	l.lintExpr(expr.Left)
+	l.lintExpr(expr.Right)
+	return nil
+}
+
+func (l *Linter) VisitCallExpr(expr *Call) any {
+	l.lintExpr(expr.Callee)
+	for _, argument := range expr.Arguments {
+		l.lintExpr(argument)
+	}
+
+	return nil
+}
+
+func (l *Linter) VisitGetExpr(expr *Get) any {
+	l.lintExpr(expr.Object)
+	return nil
+}
+
+func (l *Linter) VisitGroupingExpr(expr *Grouping) any {
+	l.lintExpr(expr.Expression)
+	return nil
+}
+
+func (l *Linter) VisitIndexExpr(expr *Index) any {
+	l.lintExpr(expr.Object)
+	l.lintExpr(expr.Index)
+
+	return nil
+}
+
+func (l *Linter) VisitListExpr(expr *List) any {
+	for _, element := range expr.Elements {
+		l.lintExpr(element)
+	}
+
+	return nil
+}
+
+func (l *Linter) VisitLiteralExpr(expr *Literal) any {
+	return nil
+}
+
+func (l *Linter) VisitLogicalExpr(expr *Logical) any {
+	l.lintExpr(expr.Left)
+	l.lintExpr(expr.Right)
+
+	return nil
+}
+
+func (l *Linter) VisitMapExpr(expr *Map) any {
+	for i, key := range expr.Keys {
+		l.lintExpr(key)
+		l.lintExpr(expr.Values[i])
+	}
+
+	return nil
+}
+
+func (l *Linter) VisitSetExpr(expr *Set) any {
+	if get, ok := expr.Value.(*Get); ok && get.Name.Lexeme == expr.Name.Lexeme && sameTarget(expr.Object, get.Object) {
+		l.warn(LintSelfAssignment, expr.Name, fmt.Sprintf("Field '%s' is assigned to itself.", expr.Name.Lexeme))
+	}
+
+	l.lintExpr(expr.Object)
+	l.lintExpr(expr.Value)
+	return nil
+}
+
+func (l *Linter) VisitSetIndexExpr(expr *SetIndex) any {
+	l.lintExpr(expr.Object)
+	l.lintExpr(expr.Index)
+	l.lintExpr(expr.Value)
+
+	return nil
+}
+
+func (l *Linter) VisitSliceExpr(expr *Slice) any {
+	l.lintExpr(expr.Object)
+	l.lintExpr(expr.Start)
+	l.lintExpr(expr.End)
+
+	return nil
+}
+
+func (l *Linter) VisitToStringExpr(expr *ToString) any {
+	l.lintExpr(expr.Expression)
+	return nil
+}
+
+func (l *Linter) VisitSuperExpr(expr *Super) any {
+	return nil
+}
+
+func (l *Linter) VisitThisExpr(expr *This) any {
+	return nil
+}
+
+func (l *Linter) VisitUnaryExpr(expr *Unary) any {
+	l.lintExpr(expr.Right)
+	return nil
+}
+
+func (l *Linter) VisitVariableExprExpr(expr *VariableExpr) any {
+	return nil
+}
@@ -0,0 +1,148 @@
+package lox_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/michalzarsm/lox-interpreter/lox"
+)
+
+// describeWarnings lists the rule, position and message of each warning.
+func describeWarnings(warnings lox.Errors) []string {
+	lines := make([]string, 0, len(warnings))
+	for _, warning := range warnings {
+		start := warning.Token.Span.Start
+		lines = append(lines, fmt.Sprintf("%s %d:%d %s", warning.Rule, start.Line, start.Column, warning.Message))
+	}
+
+	return lines
+}
+
+func TestLintRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		{
+			name:   "unused-variable",
+			source: "fun f() {\n  var x = 1;\n}\nf();\n",
+			want:   []string{"unused-variable 2:7 Variable 'x' is never used."},
+		},
+		{
+			name:   "unused-variable disabled on the line",
+			source: "fun f() {\n  var x = 1; // lint-disable-line unused-variable\n}\nf();\n",
+		},
+		{
+			name:   "shadowed-variable",
+			source: "var a = 1;\n{\n  var a = 2;\n  print a;\n}\nprint a;\n",
+			want:   []string{"shadowed-variable 3:7 'a' shadows the variable declared on line 1."},
+		},
+		{
+			name:   "shadowed-variable disabled on the next line",
+			source: "var a = 1;\n{\n  // lint-disable-next-line shadowed-variable\n  var a = 2;\n  print a;\n}\nprint a;\n",
+		},
+		{
+			name:   "unreachable-code",
+			source: "fun f() {\n  return 1;\n  print 2;\n  print 3;\n}\nf();\n",
+			want:   []string{"unreachable-code 3:3 Unreachable code after return."},
+		},
+		{
+			name:   "unreachable-code disabled for the file",
+			source: "// lint-disable unreachable-code\nfun f() {\n  return 1;\n  print 2;\n}\nf();\n",
+		},
+		{
+			name:   "self-assignment",
+			source: "var a = 1;\na = a;\nclass P {\n  init() {\n    this.x = 1;\n    this.x = this.x;\n  }\n}\n",
+			want: []string{
+				"self-assignment 2:1 Variable 'a' is assigned to itself.",
+				"self-assignment 6:10 Field 'x' is assigned to itself.",
+			},
+		},
+		{
+			name:   "self-assignment disabled on the line",
+			source: "var a = 1;\na = a; // lint-disable-line self-assignment\n",
+		},
+		{
+			name:   "nil-comparison",
+			source: "print nil == nil;\nprint (1) != nil;\n",
+			want: []string{
+				"nil-comparison 1:7 Comparison with nil is always true.",
+				"nil-comparison 2:7 Comparison with nil is always true.",
+			},
+		},
+		{
+			name:   "nil-comparison disabled on the next line",
+			source: "// lint-disable-next-line nil-comparison\nprint nil == nil;\n",
+		},
+		{
+			name:   "number-string-concat",
+			source: "print 1 + \"a\";\nprint \"a\" + 1;\nprint \"a\" + \"b\";\n",
+			want: []string{
+				"number-string-concat 1:7 Adding a number and a string fails at runtime.",
+				"number-string-concat 2:7 Adding a number and a string fails at runtime.",
+			},
+		},
+		{
+			name:   "number-string-concat disabled for the file",
+			source: "// lint-disable number-string-concat\nprint 1 + \"a\";\n",
+		},
+		{
+			name:   "directive for another rule",
+			source: "print 1 + \"a\"; // lint-disable-line unused-variable, nil-comparison\n",
+			want:   []string{"number-string-concat 1:7 Adding a number and a string fails at runtime."},
+		},
+		{
+			name:   "directive without rules",
+			source: "// lint-disable-next-line\nprint nil == 1 + \"a\";\nprint nil == nil;\n",
+			want:   []string{"nil-comparison 3:7 Comparison with nil is always true."},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			warnings, err := lox.Lint(test.source)
+			if err != nil {
+				t.Fatalf("lint: %v", err)
+			}
+
+			got := describeWarnings(warnings)
+			if len(got) == 0 && len(test.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("warnings are %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestLintReport(t *testing.T) {
+	warnings, err := lox.Lint("var unused = 1 + \"a\";\n")
+	if err != nil {
+		t.Fatalf("lint: %v", err)
+	}
+
+	if len(warnings) != 2 {
+		t.Fatalf("got %d warnings, want 2", len(warnings))
+	}
+	for _, warning := range warnings {
+		if warning.Type != lox.LintWarning || warning.ExitCode != 1 {
+			t.Errorf("warning %q has type %s and exit code %d, want %s and 1", warning.Message, warning.Type, warning.ExitCode, lox.LintWarning)
+		}
+	}
+
+	want := "[line 1] Warning at 'unused': Variable 'unused' is never used. [unused-variable]\n" +
+		"[line 1] Warning at '+': Adding a number and a string fails at runtime. [number-string-concat]"
+	if warnings.Error() != want {
+		t.Errorf("report is\n%s\nwant\n%s", warnings.Error(), want)
+	}
+
+	_, err = lox.Lint("print ;")
+	var errs lox.Errors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].ExitCode != 65 {
+		t.Errorf("linting a syntax error returns %v, want one error with exit code 65", err)
+	}
+}
@@ -0,0 +1,200 @@
+package lox
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// LoxList is the runtime value of a list literal. Like instances, lists
+// are shared by reference and compare equal only to themselves.
+type LoxList struct {
+	elements []Value
+}
+
+func newLoxList(elements []Value) *LoxList {
+	return &LoxList{
+		elements: elements,
+	}
+}
+
+// get returns the method called name, bound to the list.
+func (l *LoxList) get(name Token) (Value, error) {
+	switch name.Lexeme {
+	case "push":
+		return newNativeFunction("push", 1, func(arguments []Value) (Value, error) {
+			l.elements = append(l.elements, arguments[0])
+			return nil, nil
+		}), nil
+	case "pop":
+		return newNativeFunction("pop", 0, func(arguments []Value) (Value, error) {
+			if len(l.elements) == 0 {
+				return nil, errors.New("Can't pop from an empty list.")
+			}
+
+			last := l.elements[len(l.elements)-1]
+			l.elements = l.elements[:len(l.elements)-1]
+			return last, nil
+		}), nil
+	case "len":
+		return newNativeFunction("len", 0, func(arguments []Value) (Value, error) {
+			return float64(len(l.elements)), nil
+		}), nil
+	case "insert":
+		return newNativeFunction("insert", 2, func(arguments []Value) (Value, error) {
+			// Inserting right after the last element is allowed.
+			i, err := l.position(arguments[0], len(l.elements)+1)
+			if err != nil {
+				return nil, err
+			}
+
+			l.elements = append(l.elements, nil)
+			copy(l.elements[i+1:], l.elements[i:])
+			l.elements[i] = arguments[1]
+			return nil, nil
+		}), nil
+	case "remove":
+		return newNativeFunction("remove", 1, func(arguments []Value) (Value, error) {
+			i, err := l.position(arguments[0], len(l.elements))
+			if err != nil {
+				return nil, err
+			}
+
+			removed := l.elements[i]
+			l.elements = append(l.elements[:i], l.elements[i+1:]...)
+			return removed, nil
+		}), nil
+	case "contains":
+		return newNativeFunction("contains", 1, func(arguments []Value) (Value, error) {
+			for _, element := range l.elements {
+				if isEqual(element, arguments[0]) {
+					return true, nil
+				}
+			}
+
+			return false, nil
+		}), nil
+	}
+
+	return nil, errors.New("Undefined property '" + name.Lexeme + "'.")
+}
+
+// position turns a Lox index into a position below limit. Negative
+// indices count back from the end of the list.
+func (l *LoxList) position(index Value, limit int) (int, error) {
+	i, err := listIndex(index, len(l.elements))
+	if err != nil {
+		return 0, err
+	}
+
+	if i < 0 || i >= limit {
+		return 0, fmt.Errorf("List index %s is out of range for a list of length %d.", stringify(index), len(l.elements))
+	}
+
+	return i, nil
+}
+
+// listIndex checks that index is a whole number and makes a negative one
+// count back from length.
+func listIndex(index Value, length int) (int, error) {
+	number, ok := index.(float64)
+	if !ok || number != float64(int(number)) {
+		return 0, errors.New("List index must be a whole number.")
+	}
+
+	i := int(number)
+	if i < 0 {
+		i += length
+	}
+
+	return i, nil
+}
+
+func (l *LoxList) getIndex(index Value) (Value, error) {
+	i, err := l.position(index, len(l.elements))
+	if err != nil {
+		return nil, err
+	}
+
+	return l.elements[i], nil
+}
+
+func (l *LoxList) setIndex(index Value, value Value) error {
+	i, err := l.position(index, len(l.elements))
+	if err != nil {
+		return err
+	}
+
+	l.elements[i] = value
+	return nil
+}
+
+// slice copies the elements from start up to but not including end into
+// a new list. A nil bound stands for the start or the end of the list,
+// and bounds beyond either end are clamped to it.
+func (l *LoxList) slice(start Value, end Value) (*LoxList, error) {
+	from, to := 0, len(l.elements)
+
+	if start != nil {
+		i, err := listIndex(start, len(l.elements))
+		if err != nil {
+			return nil, err
+		}
+		from = min(max(i, 0), len(l.elements))
+	}
+
+	if end != nil {
+		i, err := listIndex(end, len(l.elements))
+		if err != nil {
+			return nil, err
+		}
+		to = min(max(i, 0), len(l.elements))
+	}
+
+	elements := make([]Value, 0, max(to-from, 0))
+	if from < to {
+		elements = append(elements, l.elements[from:to]...)
+	}
+
+	return newLoxList(elements), nil
+}
+
+func (l *LoxList) String() string {
+	return formatElement(l, map[Value]bool{})
+}
+
+// formatElement formats a value held in a list or a map. printing holds
+// the containers already being printed further up, so a container that
+// holds itself prints as [...] or {...} instead of recursing forever.
+func formatElement(value Value, printing map[Value]bool) string {
+	switch container := value.(type) {
+	case *LoxList:
+		if printing[container] {
+			return "[...]"
+		}
+		printing[container] = true
+		defer delete(printing, container)
+
+		elements := make([]string, 0, len(container.elements))
+		for _, element := range container.elements {
+			elements = append(elements, formatElement(element, printing))
+		}
+
+		return "[" + strings.Join(elements, ", ") + "]"
+	case *LoxMap:
+		if printing[container] {
+			return "{...}"
+		}
+		printing[container] = true
+		defer delete(printing, container)
+
+		entries := make([]string, 0, len(container.keys))
+		for _, key := range container.keys {
+			entries = append(entries, formatElement(key, printing)+": "+formatElement(container.entries[key], printing))
+		}
+
+		return "{" + strings.Join(entries, ", ") + "}"
+	}
+
+	return formatConstant(value)
+}
@@ -0,0 +1,91 @@
+package lox
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Lox struct {
+	errors []Error
+}
+
+type ErrorType = string
+
+const (
+	SyntaxError       ErrorType = "SyntaxError"
+	RuntimeError      ErrorType = "RuntimeError"
+	ValueConvertError ErrorType = "ValueConvertError"
+	LintWarning       ErrorType = "Warning"
+)
+
+type Error struct {
+	Type     ErrorType
+	Token    Token
+	Message  string
+	ExitCode int
+
+	// Notes are extra hints shown below the error by the pretty
+	// diagnostics format.
+	Notes []string
+
+	// Rule is the lint rule that reported a LintWarning.
+	Rule LintRule
+}
+
+func newRuntimeError(token Token, message string) Error {
+	return Error{Type: RuntimeError, Token: token, Message: message, ExitCode: 70}
+}
+
+func (e Error) Error() string {
+	if e.Type == LintWarning {
+		if e.Token.Lexeme != "" {
+			return fmt.Sprintf("[line %d] Warning at '%s': %s [%s]", e.Token.Line, e.Token.Lexeme, e.Message, e.Rule)
+		}
+
+		return fmt.Sprintf("[line %d] Warning: %s [%s]", e.Token.Line, e.Message, e.Rule)
+	}
+
+	if e.Type == RuntimeError {
+		return fmt.Sprintf("%s\n[line %d]", e.Message, e.Token.Line)
+	}
+
+	if e.Token.Type == EOF {
+		return fmt.Sprintf("[line %d] Error at end: %s", e.Token.Line, e.Message)
+	}
+
+	if e.Token.Lexeme != "" {
+		return fmt.Sprintf("[line %d] Error at '%s': %s", e.Token.Line, e.Token.Lexeme, e.Message)
+	}
+
+	return fmt.Sprintf("[line %d] Error: %s", e.Token.Line, e.Message)
+}
+
+// Errors is returned when a run fails. Static errors are all collected
+// before giving up, so there may be several; a runtime error is always
+// alone.
+type Errors []Error
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+func newLox() *Lox {
+	return &Lox{
+		errors: make([]Error, 0),
+	}
+}
+
+// failed returns the collected errors as a single error value, or nil if
+// there are none.
+func (lox *Lox) failed() error {
+	if len(lox.errors) == 0 {
+		return nil
+	}
+
+	return append(Errors(nil), lox.errors...)
+}
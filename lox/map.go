@@ -0,0 +1,103 @@
+package lox
+
+import (
+	"errors"
+	"math"
+)
+
+// LoxMap is the runtime value of a map literal. It keeps its entries in
+// the order their keys were first set.
+//
+// Keys are compared the way isEqual compares values, which is Go's own
+// interface equality, so a Go map keyed by Value hashes them consistently:
+// numbers, strings, booleans and nil by value and every other runtime type
+// by identity.
+type LoxMap struct {
+	keys    []Value
+	entries map[Value]Value
+}
+
+func newLoxMap() *LoxMap {
+	return &LoxMap{
+		keys:    make([]Value, 0),
+		entries: make(map[Value]Value),
+	}
+}
+
+// get returns the method called name, bound to the map.
+func (m *LoxMap) get(name Token) (Value, error) {
+	switch name.Lexeme {
+	case "get":
+		return newNativeFunction("get", 1, func(arguments []Value) (Value, error) {
+			return m.entries[arguments[0]], nil
+		}), nil
+	case "set":
+		return newNativeFunction("set", 2, func(arguments []Value) (Value, error) {
+			return nil, m.set(arguments[0], arguments[1])
+		}), nil
+	case "has":
+		return newNativeFunction("has", 1, func(arguments []Value) (Value, error) {
+			_, ok := m.entries[arguments[0]]
+			return ok, nil
+		}), nil
+	case "delete":
+		return newNativeFunction("delete", 1, func(arguments []Value) (Value, error) {
+			return m.delete(arguments[0]), nil
+		}), nil
+	case "keys":
+		return newNativeFunction("keys", 0, func(arguments []Value) (Value, error) {
+			return newLoxList(append([]Value(nil), m.keys...)), nil
+		}), nil
+	case "values":
+		return newNativeFunction("values", 0, func(arguments []Value) (Value, error) {
+			values := make([]Value, 0, len(m.keys))
+			for _, key := range m.keys {
+				values = append(values, m.entries[key])
+			}
+
+			return newLoxList(values), nil
+		}), nil
+	case "len":
+		return newNativeFunction("len", 0, func(arguments []Value) (Value, error) {
+			return float64(len(m.keys)), nil
+		}), nil
+	}
+
+	return nil, errors.New("Undefined property '" + name.Lexeme + "'.")
+}
+
+// set adds or replaces the entry for key. NaN is refused as a key because
+// it isn't equal to itself, so its entry could never be found again.
+func (m *LoxMap) set(key Value, value Value) error {
+	if number, ok := key.(float64); ok && math.IsNaN(number) {
+		return errors.New("Map key can't be NaN.")
+	}
+
+	if _, ok := m.entries[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+
+	m.entries[key] = value
+	return nil
+}
+
+// delete removes the entry for key and reports whether there was one.
+func (m *LoxMap) delete(key Value) bool {
+	if _, ok := m.entries[key]; !ok {
+		return false
+	}
+
+	delete(m.entries, key)
+	for i, k := range m.keys {
+		if isEqual(k, key) {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+			break
+		}
+	}
+
+	return true
+}
+
+func (m *LoxMap) String() string {
+	return formatElement(m, map[Value]bool{})
+}
@@ -0,0 +1,275 @@
+package lox
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// NativeFunction is a Lox callable implemented in Go.
+type NativeFunction struct {
+	name     string
+	params   int
+	function func(arguments []Value) (Value, error)
+}
+
+func newNativeFunction(name string, arity int, function func(arguments []Value) (Value, error)) *NativeFunction {
+	return &NativeFunction{
+		name:     name,
+		params:   arity,
+		function: function,
+	}
+}
+
+func (n *NativeFunction) arity() int {
+	return n.params
+}
+
+func (n *NativeFunction) call(interpreter *Interpreter, paren Token, arguments []any) any {
+	value, err := n.function(arguments)
+	if err != nil {
+		panic(newRuntimeError(paren, err.Error()))
+	}
+
+	return value
+}
+
+func (n *NativeFunction) String() string {
+	return "<native fn>"
+}
+
+// DefineNative defines a function called name that takes exactly arity
+// arguments and is implemented by function. The arguments are passed as
+// plain Lox values. An error returned by function becomes a Lox runtime
+// error reported at the call site.
+func (e *Environment) DefineNative(name string, arity int, function func(arguments []Value) (Value, error)) {
+	e.define(name, newNativeFunction(name, arity, function))
+}
+
+// DefineFunc defines a function called name that calls the Go function fn.
+// The Lox arity is the number of parameters fn declares. Each parameter
+// may be a number type, string, bool, *LoxInstance or Value, and Lox
+// arguments are converted to it, failing with a runtime error when they
+// don't fit. fn may return nothing, a single value, an error, or a value
+// followed by an error; the value may be of any of the parameter types
+// and is converted back to a Lox value.
+func (e *Environment) DefineFunc(name string, fn any) error {
+	function := reflect.ValueOf(fn)
+	functionType := function.Type()
+
+	if functionType.Kind() != reflect.Func {
+		return fmt.Errorf("%s: expected a function, got %s", name, functionType)
+	}
+
+	if functionType.IsVariadic() {
+		return fmt.Errorf("%s: variadic functions are not supported", name)
+	}
+
+	for i := 0; i < functionType.NumIn(); i++ {
+		if !isConvertibleType(functionType.In(i)) {
+			return fmt.Errorf("%s: unsupported parameter type %s", name, functionType.In(i))
+		}
+	}
+
+	returnsValue, returnsError, err := checkResults(functionType)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	e.DefineNative(name, functionType.NumIn(), func(arguments []Value) (Value, error) {
+		in := make([]reflect.Value, len(arguments))
+		for i, argument := range arguments {
+			converted, err := fromLoxValue(argument, functionType.In(i))
+			if err != nil {
+				return nil, fmt.Errorf("Argument %d to '%s' %s", i+1, name, err.Error())
+			}
+			in[i] = converted
+		}
+
+		out := function.Call(in)
+
+		if returnsError {
+			if err, _ := out[len(out)-1].Interface().(error); err != nil {
+				return nil, err
+			}
+		}
+
+		if !returnsValue {
+			return nil, nil
+		}
+
+		return toLoxValue(out[0]), nil
+	})
+
+	return nil
+}
+
+var (
+	errorType    = reflect.TypeFor[error]()
+	valueType    = reflect.TypeFor[Value]()
+	instanceType = reflect.TypeFor[*LoxInstance]()
+)
+
+func checkResults(functionType reflect.Type) (returnsValue bool, returnsError bool, err error) {
+	switch functionType.NumOut() {
+	case 0:
+		return false, false, nil
+	case 1:
+		if functionType.Out(0) == errorType {
+			return false, true, nil
+		}
+
+		if !isConvertibleType(functionType.Out(0)) {
+			return false, false, fmt.Errorf("unsupported result type %s", functionType.Out(0))
+		}
+
+		return true, false, nil
+	case 2:
+		if functionType.Out(1) != errorType {
+			return false, false, errors.New("the second result must be an error")
+		}
+
+		if !isConvertibleType(functionType.Out(0)) {
+			return false, false, fmt.Errorf("unsupported result type %s", functionType.Out(0))
+		}
+
+		return true, true, nil
+	}
+
+	return false, false, errors.New("too many results")
+}
+
+func isConvertibleType(t reflect.Type) bool {
+	if t == valueType || t == instanceType {
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+
+	return false
+}
+
+// fromLoxValue converts a Lox value to the Go type t. The error message is
+// meant to follow the name of the argument.
+func fromLoxValue(value Value, t reflect.Type) (reflect.Value, error) {
+	if t == valueType {
+		if value == nil {
+			return reflect.Zero(t), nil
+		}
+
+		return reflect.ValueOf(value), nil
+	}
+
+	if t == instanceType {
+		if value == nil {
+			return reflect.Zero(t), nil
+		}
+
+		instance, ok := value.(*LoxInstance)
+		if !ok {
+			return reflect.Value{}, errors.New("must be an instance.")
+		}
+
+		return reflect.ValueOf(instance), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return reflect.Value{}, errors.New("must be a boolean.")
+		}
+
+		return reflect.ValueOf(b).Convert(t), nil
+	case reflect.String:
+		s, ok := value.(string)
+		if !ok {
+			return reflect.Value{}, errors.New("must be a string.")
+		}
+
+		return reflect.ValueOf(s).Convert(t), nil
+	case reflect.Float32, reflect.Float64:
+		num, ok := value.(float64)
+		if !ok {
+			return reflect.Value{}, errors.New("must be a number.")
+		}
+
+		return reflect.ValueOf(num).Convert(t), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		num, err := wholeNumber(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		// 2^63 is exact as a float64, unlike the largest int64.
+		if num < math.MinInt64 || num >= -math.MinInt64 || reflect.Zero(t).OverflowInt(int64(num)) {
+			return reflect.Value{}, errors.New("is out of range.")
+		}
+
+		return reflect.ValueOf(int64(num)).Convert(t), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		num, err := wholeNumber(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		if num < 0 || num >= 2*-math.MinInt64 || reflect.Zero(t).OverflowUint(uint64(num)) {
+			return reflect.Value{}, errors.New("is out of range.")
+		}
+
+		return reflect.ValueOf(uint64(num)).Convert(t), nil
+	}
+
+	return reflect.Value{}, fmt.Errorf("can't be converted to %s.", t)
+}
+
+func wholeNumber(value Value) (float64, error) {
+	num, ok := value.(float64)
+	if !ok || num != math.Trunc(num) || math.IsInf(num, 0) {
+		return 0, errors.New("must be a whole number.")
+	}
+
+	return num, nil
+}
+
+// toLoxValue converts the result of a Go function to a Lox value.
+func toLoxValue(value reflect.Value) Value {
+	switch value.Kind() {
+	case reflect.Bool:
+		return value.Bool()
+	case reflect.String:
+		return value.String()
+	case reflect.Float32, reflect.Float64:
+		return value.Float()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint())
+	case reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+
+		return toLoxValue(value.Elem())
+	case reflect.Pointer:
+		if value.IsNil() {
+			return nil
+		}
+	}
+
+	return value.Interface()
+}
+
+// defineGlobals adds the functions every Lox program can rely on.
+func defineGlobals(globals *Environment) {
+	globals.DefineNative("clock", 0, func(arguments []Value) (Value, error) {
+		return float64(time.Now().UnixNano()) / float64(time.Second), nil
+	})
+}
@@ -0,0 +1,112 @@
+package lox_test
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/michalzarsm/lox-interpreter/lox"
+)
+
+func TestDefineFuncConversions(t *testing.T) {
+	tests := []struct {
+		name   string
+		fn     any
+		source string
+		want   lox.Value
+		err    string
+	}{
+		{name: "int", fn: func(n int) int { return n * 2 }, source: "f(21);", want: 42.0},
+		{name: "int from a fraction", fn: func(n int) int { return n }, source: "f(1.5);", err: "Argument 1 to 'f' must be a whole number."},
+		{name: "int from a string", fn: func(n int) int { return n }, source: `f("1");`, err: "Argument 1 to 'f' must be a whole number."},
+		{name: "int8 in range", fn: func(n int8) int8 { return n }, source: "f(-128);", want: -128.0},
+		{name: "int8 out of range", fn: func(n int8) int8 { return n }, source: "f(200);", err: "Argument 1 to 'f' is out of range."},
+		{name: "uint from a negative", fn: func(n uint) uint { return n }, source: "f(-1);", err: "Argument 1 to 'f' is out of range."},
+		{name: "uint64 too large", fn: func(n uint64) uint64 { return n }, source: "f(18446744073709551616);", err: "Argument 1 to 'f' is out of range."},
+		{name: "uint64 above the int64 range", fn: func(n uint64) uint64 { return n }, source: "f(9223372036854775808);", want: 9223372036854775808.0},
+		{name: "int64 too large", fn: func(n int64) int64 { return n }, source: "f(9223372036854775808);", err: "Argument 1 to 'f' is out of range."},
+		{name: "int from infinity", fn: func(n int) int { return n }, source: "f(1/0);", err: "Argument 1 to 'f' must be a whole number."},
+		{name: "int from NaN", fn: func(n int) int { return n }, source: "f(0/0);", err: "Argument 1 to 'f' must be a whole number."},
+		{name: "float32", fn: func(x float32) float32 { return x * 2 }, source: "f(0.25);", want: 0.5},
+		{name: "float64 from nil", fn: func(x float64) float64 { return x }, source: "f(nil);", err: "Argument 1 to 'f' must be a number."},
+		{name: "string", fn: func(s string) string { return s + "!" }, source: `f("hi");`, want: "hi!"},
+		{name: "string from a number", fn: func(s string) string { return s }, source: "f(1);", err: "Argument 1 to 'f' must be a string."},
+		{name: "bool", fn: func(b bool) bool { return !b }, source: "f(true);", want: false},
+		{name: "bool from nil", fn: func(b bool) bool { return b }, source: "f(nil);", err: "Argument 1 to 'f' must be a boolean."},
+		{name: "second argument", fn: func(a string, b bool) bool { return b }, source: `f("a", "b");`, err: "Argument 2 to 'f' must be a boolean."},
+		{name: "Value", fn: func(v lox.Value) lox.Value { return v }, source: `f("any");`, want: "any"},
+		{name: "Value from nil", fn: func(v lox.Value) bool { return v == nil }, source: "f(nil);", want: true},
+		{name: "instance", fn: func(i *lox.LoxInstance) bool { return i != nil }, source: "class A {} f(A());", want: true},
+		{name: "instance from nil", fn: func(i *lox.LoxInstance) bool { return i != nil }, source: "f(nil);", want: false},
+		{name: "instance from a number", fn: func(i *lox.LoxInstance) bool { return true }, source: "f(1);", err: "Argument 1 to 'f' must be an instance."},
+		{name: "nil instance result", fn: func() *lox.LoxInstance { return nil }, source: "f();", want: nil},
+		{name: "no results", fn: func() {}, source: "f();", want: nil},
+		{name: "error result", fn: func() error { return errors.New("boom") }, source: "f();", err: "boom"},
+		{name: "nil error result", fn: func() error { return nil }, source: "f();", want: nil},
+		{name: "value and error", fn: strconv.Itoa, source: "f(7);", want: "7"},
+		{name: "value and failing error", fn: strconv.Atoi, source: `f("x");`, err: `strconv.Atoi: parsing "x": invalid syntax`},
+		{name: "too few arguments", fn: func(a, b int) int { return a + b }, source: "f(1);", err: "Expected 2 arguments but got 1."},
+		{name: "too many arguments", fn: func() {}, source: "f(1);", err: "Expected 0 arguments but got 1."},
+	}
+
+	for _, backend := range []lox.Backend{lox.BackendTree, lox.BackendVM} {
+		for _, test := range tests {
+			t.Run(string(backend)+"/"+test.name, func(t *testing.T) {
+				runtime := lox.NewRuntime()
+				runtime.Backend = backend
+				if err := runtime.Globals().DefineFunc("f", test.fn); err != nil {
+					t.Fatalf("define: %v", err)
+				}
+
+				value, err := runtime.Eval(test.source)
+				if test.err != "" {
+					if err == nil || !strings.Contains(err.Error(), test.err) {
+						t.Fatalf("error is %v, want one containing %q", err, test.err)
+					}
+					return
+				}
+
+				if err != nil {
+					t.Fatalf("eval: %v", err)
+				}
+				if value != test.want {
+					t.Errorf("value is %#v, want %#v", value, test.want)
+				}
+			})
+		}
+	}
+}
+
+func TestDefineFuncRejectsSignatures(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   any
+		err  string
+	}{
+		{name: "not a function", fn: 42, err: "f: expected a function, got int"},
+		{name: "variadic", fn: func(xs ...int) {}, err: "f: variadic functions are not supported"},
+		{name: "slice parameter", fn: func(xs []int) {}, err: "f: unsupported parameter type []int"},
+		{name: "map parameter", fn: func(m map[string]int) {}, err: "f: unsupported parameter type map[string]int"},
+		{name: "error parameter", fn: func(err error) {}, err: "f: unsupported parameter type error"},
+		{name: "unsupported result", fn: func() []string { return nil }, err: "f: unsupported result type []string"},
+		{name: "unsupported result before an error", fn: func() (chan int, error) { return nil, nil }, err: "f: unsupported result type chan int"},
+		{name: "second result not an error", fn: func() (int, int) { return 0, 0 }, err: "f: the second result must be an error"},
+		{name: "too many results", fn: func() (int, string, error) { return 0, "", nil }, err: "f: too many results"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			runtime := lox.NewRuntime()
+
+			err := runtime.Globals().DefineFunc("f", test.fn)
+			if err == nil || err.Error() != test.err {
+				t.Fatalf("error is %v, want %q", err, test.err)
+			}
+
+			if _, err := runtime.Eval("f;"); err == nil {
+				t.Error("the rejected function was defined anyway")
+			}
+		})
+	}
+}
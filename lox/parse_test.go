@@ -0,0 +1,74 @@
+package lox_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/michalzarsm/lox-interpreter/lox"
+)
+
+// TestParseGolden parses every program in testdata/parse and compares the
+// AST, printed as S-expressions and as JSON, with the .sexpr and .json
+// files next to it. Run the test with -update to accept a deliberate
+// change to either format.
+func TestParseGolden(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "parse", "*.lox"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no programs in testdata/parse")
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			source, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			statements, err := lox.Parse(string(source))
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+
+			var sexpr strings.Builder
+			printer := lox.NewAstPrinter()
+			for _, statement := range statements {
+				sexpr.WriteString(printer.PrintStmt(statement) + "\n")
+			}
+
+			json, err := lox.MarshalAst(statements)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+
+			base := strings.TrimSuffix(path, ".lox")
+			compareGolden(t, base+".sexpr", sexpr.String())
+			compareGolden(t, base+".json", string(json)+"\n")
+		})
+	}
+}
+
+// compareGolden compares got with the golden file, or rewrites the file
+// with -update.
+func compareGolden(t *testing.T, golden string, got string) {
+	t.Helper()
+
+	if *update {
+		if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("%v; run the test with -update to create it", err)
+	}
+	if got != string(want) {
+		t.Errorf("output differs from %s\ngot:\n%s\nwant:\n%s", golden, got, want)
+	}
+}
@@ -0,0 +1,800 @@
+package lox
+
+import (
+	"reflect"
+	"strings"
+)
+
+// parseError is panicked by the parser to unwind out of a statement it
+// can't make sense of. declaration recovers it and resynchronizes.
+type parseError struct{}
+
+type Parser struct {
+	Lox     *Lox
+	tokens  []Token
+	current int
+
+	// loops holds the labels of the loops enclosing the statement being
+	// parsed, innermost last, with an empty token for an unlabelled loop.
+	// It starts out empty again inside a function body.
+	loops []Token
+}
+
+func newParser(tokens []Token, lox *Lox) *Parser {
+	return &Parser{
+		Lox:     lox,
+		tokens:  tokens,
+		current: 0,
+	}
+}
+
+func (p *Parser) parse() []Stmt {
+	statements := make([]Stmt, 0)
+	for !p.isAtEnd() {
+		statement := p.declaration()
+		if statement != nil {
+			statements = append(statements, statement)
+		}
+	}
+
+	return statements
+}
+
+// parseExpression parses the tokens as a single expression, returning nil
+// after reporting a syntax error if they are anything else.
+func (p *Parser) parseExpression() (expr Expr) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(parseError); !ok {
+				panic(r)
+			}
+
+			expr = nil
+		}
+	}()
+
+	expr = p.expression()
+	if !p.isAtEnd() {
+		panic(p.error(p.peek(), "Expect end of expression.", 65))
+	}
+
+	return expr
+}
+
+func (p *Parser) expression() Expr {
+	return p.assignment()
+}
+
+// declaration parses a single declaration or statement. When it hits a
+// syntax error it skips ahead to the next statement boundary and returns
+// nil, so the caller can carry on and report further errors.
+func (p *Parser) declaration() (stmt Stmt) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(parseError); !ok {
+				panic(r)
+			}
+
+			p.synchronize()
+			stmt = nil
+		}
+	}()
+
+	if p.match(CLASS) {
+		return p.classDeclaration()
+	}
+
+	if p.match(FUN) {
+		fun := p.previous()
+		function := p.function("function")
+		function.span.Start = fun.Span.Start
+		return function
+	}
+
+	if p.match(VAR) {
+		return p.varDeclaration()
+	}
+
+	return p.statement()
+}
+
+func (p *Parser) classDeclaration() Stmt {
+	class := p.previous()
+	name := p.consume(IDENTIFIER, "Expect class name.")
+
+	var superclass Expr
+	if p.match(LESS) {
+		p.consume(IDENTIFIER, "Expect superclass name.")
+		superclass = &VariableExpr{p.previous(), p.previous().Span}
+	}
+
+	p.consume(LEFT_BRACE, "Expect '{' before class body.")
+
+	methods := make([]*Function, 0)
+	for !p.check(RIGHT_BRACE) && !p.isAtEnd() {
+		methods = append(methods, p.function("method"))
+	}
+
+	p.consume(RIGHT_BRACE, "Expect '}' after class body.")
+
+	return &Class{name, superclass, methods, p.spanFrom(class)}
+}
+
+func (p *Parser) statement() Stmt {
+	if p.check(IDENTIFIER) && p.checkNext(COLON) {
+		return p.labelledStatement()
+	}
+
+	if p.match(BREAK) {
+		return p.breakStatement()
+	}
+
+	if p.match(CONTINUE) {
+		return p.continueStatement()
+	}
+
+	if p.match(FOR) {
+		return p.forStatement(p.previous(), Token{})
+	}
+
+	if p.match(IF) {
+		return p.ifStatement()
+	}
+
+	if p.match(PRINT) {
+		return p.printStatement()
+	}
+
+	if p.match(RETURN) {
+		return p.returnStatement()
+	}
+
+	if p.match(WHILE) {
+		return p.whileStatement(p.previous(), Token{})
+	}
+
+	if !p.startsMap() && p.match(LEFT_BRACE) {
+		brace := p.previous()
+		return &Block{p.block(), p.spanFrom(brace)}
+	}
+
+	return p.expressionStatement()
+}
+
+// startsMap reports whether the current token is a '{' that opens a map
+// literal rather than a block, which is the case when a literal key and a
+// ':' follow it. A block can't start that way, while a '{' followed by a
+// name and a ':' is a block starting with a labelled loop.
+func (p *Parser) startsMap() bool {
+	if !p.check(LEFT_BRACE) || p.current+2 >= len(p.tokens) {
+		return false
+	}
+
+	switch p.tokens[p.current+1].Type {
+	case STRING, NUMBER, TRUE, FALSE, NIL:
+		return p.tokens[p.current+2].Type == COLON
+	}
+
+	return false
+}
+
+// labelledStatement parses a loop preceded by "label:", which break and
+// continue statements inside it can name to leave or restart it from a
+// nested loop.
+func (p *Parser) labelledStatement() Stmt {
+	label := p.advance()
+	p.advance()
+
+	for _, enclosing := range p.loops {
+		if enclosing.Lexeme == label.Lexeme {
+			p.error(label, "Already a loop labelled '"+label.Lexeme+"' around this one.", 65)
+		}
+	}
+
+	if p.match(FOR) {
+		return p.forStatement(label, label)
+	}
+
+	if p.match(WHILE) {
+		return p.whileStatement(label, label)
+	}
+
+	panic(p.error(p.peek(), "Expect loop after label.", 65, "Only 'for' and 'while' loops can be labelled."))
+}
+
+func (p *Parser) breakStatement() Stmt {
+	keyword := p.previous()
+	label := p.loopLabel(keyword)
+	p.consume(SEMICOLON, "Expect ';' after 'break'.")
+
+	return &Break{keyword, label, p.spanFrom(keyword)}
+}
+
+func (p *Parser) continueStatement() Stmt {
+	keyword := p.previous()
+	label := p.loopLabel(keyword)
+	p.consume(SEMICOLON, "Expect ';' after 'continue'.")
+
+	return &Continue{keyword, label, p.spanFrom(keyword)}
+}
+
+// loopLabel parses the optional label after break or continue and checks
+// that there is a loop for the statement to apply to.
+func (p *Parser) loopLabel(keyword Token) Token {
+	var label Token
+	if p.match(IDENTIFIER) {
+		label = p.previous()
+	}
+
+	if len(p.loops) == 0 {
+		p.error(keyword, "Can't use '"+keyword.Lexeme+"' outside of a loop.", 65)
+		return label
+	}
+
+	if label.Lexeme == "" {
+		return label
+	}
+
+	for _, enclosing := range p.loops {
+		if enclosing.Lexeme == label.Lexeme {
+			return label
+		}
+	}
+
+	p.error(label, "No enclosing loop labelled '"+label.Lexeme+"'.", 65)
+	return label
+}
+
+// loopBody parses the body of a loop with the given label.
+func (p *Parser) loopBody(label Token) Stmt {
+	p.loops = append(p.loops, label)
+	defer func() {
+		p.loops = p.loops[:len(p.loops)-1]
+	}()
+
+	return p.statement()
+}
+
+func (p *Parser) forStatement(start Token, label Token) Stmt {
+	p.consume(LEFT_PAREN, "Expect '(' after 'for'.")
+
+	var initializer Stmt
+	if p.match(SEMICOLON) {
+		initializer = nil
+	} else if p.match(VAR) {
+		initializer = p.varDeclaration()
+	} else {
+		initializer = p.expressionStatement()
+	}
+
+	var condition Expr
+	if !p.check(SEMICOLON) {
+		condition = p.expression()
+	}
+	p.consume(SEMICOLON, "Expect ';' after loop condition.")
+
+	var increment Expr
+	if !p.check(RIGHT_PAREN) {
+		increment = p.expression()
+	}
+	p.consume(RIGHT_PAREN, "Expect ')' after for clauses.")
+
+	body := p.loopBody(label)
+
+	return &For{initializer, condition, increment, body, label, p.spanFrom(start)}
+}
+
+func (p *Parser) ifStatement() Stmt {
+	keyword := p.previous()
+	p.consume(LEFT_PAREN, "Expect '(' after 'if'.")
+	condition := p.expression()
+	p.consume(RIGHT_PAREN, "Expect ')' after if condition.")
+
+	thenBranch := p.statement()
+	var elseBranch Stmt
+	if p.match(ELSE) {
+		elseBranch = p.statement()
+	}
+
+	return &If{condition, thenBranch, elseBranch, p.spanFrom(keyword)}
+}
+
+func (p *Parser) whileStatement(start Token, label Token) Stmt {
+	p.consume(LEFT_PAREN, "Expect '(' after 'while'.")
+	condition := p.expression()
+	p.consume(RIGHT_PAREN, "Expect ')' after condition.")
+	body := p.loopBody(label)
+
+	return &While{condition, body, label, p.spanFrom(start)}
+}
+
+func (p *Parser) printStatement() *Print {
+	keyword := p.previous()
+	value := p.expression()
+	p.consume(SEMICOLON, "Expect ';' after value.")
+	return &Print{Expression: value, span: p.spanFrom(keyword)}
+}
+
+func (p *Parser) returnStatement() Stmt {
+	keyword := p.previous()
+
+	var value Expr
+	if !p.check(SEMICOLON) {
+		value = p.expression()
+	}
+
+	p.consume(SEMICOLON, "Expect ';' after return value.")
+
+	return &Return{keyword, value, p.spanFrom(keyword)}
+}
+
+func (p *Parser) varDeclaration() Stmt {
+	keyword := p.previous()
+	name := p.consume(IDENTIFIER, "Expect variable name.")
+
+	var initializer Expr
+
+	if p.match(EQUAL) {
+		initializer = p.expression()
+	}
+
+	p.consume(SEMICOLON, "Expect ';' after variable declaration.")
+
+	return &VariableStmt{name, initializer, p.spanFrom(keyword)}
+
+}
+
+func (p *Parser) expressionStatement() *Expression {
+	start := p.peek()
+	expr := p.expression()
+	p.consume(SEMICOLON, "Expect ';' after expression.")
+	return &Expression{Expression: expr, span: p.spanFrom(start)}
+}
+
+func (p *Parser) function(kind string) *Function {
+	name := p.consume(IDENTIFIER, "Expect "+kind+" name.")
+	p.consume(LEFT_PAREN, "Expect '(' after "+kind+" name.")
+
+	parameters := make([]Token, 0)
+	if !p.check(RIGHT_PAREN) {
+		for {
+			if len(parameters) >= 255 {
+				p.error(p.peek(), "Can't have more than 255 parameters.", 65)
+			}
+
+			parameters = append(parameters, p.consume(IDENTIFIER, "Expect parameter name."))
+
+			if !p.match(COMMA) {
+				break
+			}
+		}
+	}
+	p.consume(RIGHT_PAREN, "Expect ')' after parameters.")
+
+	p.consume(LEFT_BRACE, "Expect '{' before "+kind+" body.")
+	body := p.functionBody()
+
+	return &Function{name, parameters, body, p.spanFrom(name)}
+}
+
+// functionBody parses the block of a function, which break and continue
+// can't leave, so no loop encloses it.
+func (p *Parser) functionBody() []Stmt {
+	enclosingLoops := p.loops
+	p.loops = nil
+	defer func() {
+		p.loops = enclosingLoops
+	}()
+
+	return p.block()
+}
+
+func (p *Parser) block() []Stmt {
+	statements := make([]Stmt, 0)
+
+	for !p.check(RIGHT_BRACE) && !p.isAtEnd() {
+		statement := p.declaration()
+		if statement != nil {
+			statements = append(statements, statement)
+		}
+	}
+
+	p.consume(RIGHT_BRACE, "Expect '}' after block.")
+
+	return statements
+}
+
+func (p *Parser) assignment() Expr {
+	expr := p.ternary()
+
+	if p.match(EQUAL) {
+		equals := p.previous()
+		value := p.assignment()
+
+		exprType := reflect.TypeOf(expr)
+
+		if exprType == reflect.TypeFor[*VariableExpr]() {
+			name := expr.(*VariableExpr).Name
+			return &Assign{name, value, expr.Span().Through(value.Span())}
+		}
+
+		if exprType == reflect.TypeFor[*Get]() {
+			get := expr.(*Get)
+			return &Set{get.Object, get.Name, value, expr.Span().Through(value.Span())}
+		}
+
+		if exprType == reflect.TypeFor[*Index]() {
+			index := expr.(*Index)
+			return &SetIndex{index.Object, index.Bracket, index.Index, value, expr.Span().Through(value.Span())}
+		}
+
+		p.error(equals, "Invalid assignment target.", 65, "Only variables, fields and list elements can be assigned to.")
+	}
+
+	return expr
+}
+
+// ternary parses "cond ? a : b". The branch in the middle can be any
+// expression, as it is closed off by the colon, while the last one is
+// another ternary, which makes the operator right-associative.
+func (p *Parser) ternary() Expr {
+	expr := p.or()
+
+	if p.match(QUESTION) {
+		trueExpr := p.expression()
+		p.consume(COLON, "Expect ':' after then branch of conditional expression.")
+		falseExpr := p.ternary()
+		expr = &Ternary{expr, trueExpr, falseExpr, expr.Span().Through(falseExpr.Span())}
+	}
+
+	return expr
+}
+
+func (p *Parser) or() Expr {
+	expr := p.and()
+
+	for p.match(OR) {
+		operator := p.previous()
+		right := p.and()
+		expr = &Logical{expr, operator, right, expr.Span().Through(right.Span())}
+	}
+
+	return expr
+}
+
+func (p *Parser) and() Expr {
+	expr := p.equality()
+
+	for p.match(AND) {
+		operator := p.previous()
+		right := p.equality()
+		expr = &Logical{expr, operator, right, expr.Span().Through(right.Span())}
+	}
+
+	return expr
+}
+
+func (p *Parser) equality() Expr {
+	expr := p.comparison()
+
+	for p.match(BANG_EQUAL, EQUAL_EQUAL) {
+		operator := p.previous()
+		right := p.comparison()
+		expr = &Binary{expr, operator, right, expr.Span().Through(right.Span())}
+	}
+
+	return expr
+}
+
+func (p *Parser) comparison() Expr {
+	expr := p.term()
+
+	for p.match(GREATER, GREATER_EQUAL, LESS, LESS_EQUAL) {
+		operator := p.previous()
+		right := p.term()
+		expr = &Binary{expr, operator, right, expr.Span().Through(right.Span())}
+	}
+
+	return expr
+}
+
+func (p *Parser) term() Expr {
+	expr := p.factor()
+
+	for p.match(MINUS, PLUS) {
+		operator := p.previous()
+		right := p.factor()
+		expr = &Binary{expr, operator, right, expr.Span().Through(right.Span())}
+	}
+
+	return expr
+}
+
+func (p *Parser) factor() Expr {
+	expr := p.unary()
+
+	for p.match(SLASH, STAR) {
+		operator := p.previous()
+		right := p.unary()
+		expr = &Binary{expr, operator, right, expr.Span().Through(right.Span())}
+	}
+
+	return expr
+}
+
+func (p *Parser) unary() Expr {
+	if p.match(BANG, MINUS) {
+		operator := p.previous()
+		right := p.unary()
+		return &Unary{operator, right, operator.Span.Through(right.Span())}
+	}
+
+	return p.call()
+}
+
+func (p *Parser) call() Expr {
+	expr := p.primary()
+
+	for {
+		if p.match(LEFT_PAREN) {
+			expr = p.finishCall(expr)
+		} else if p.match(DOT) {
+			name := p.consume(IDENTIFIER, "Expect property name after '.'.")
+			expr = &Get{expr, name, expr.Span().Through(name.Span)}
+		} else if p.match(LEFT_BRACKET) {
+			expr = p.finishIndex(expr)
+		} else {
+			break
+		}
+	}
+
+	return expr
+}
+
+func (p *Parser) finishCall(callee Expr) Expr {
+	arguments := make([]Expr, 0)
+
+	if !p.check(RIGHT_PAREN) {
+		for {
+			if len(arguments) >= 255 {
+				p.error(p.peek(), "Can't have more than 255 arguments.", 65)
+			}
+
+			arguments = append(arguments, p.expression())
+
+			if !p.match(COMMA) {
+				break
+			}
+		}
+	}
+
+	paren := p.consume(RIGHT_PAREN, "Expect ')' after arguments.")
+
+	return &Call{callee, paren, arguments, callee.Span().Through(paren.Span)}
+}
+
+// finishIndex parses what follows the '[' after object: either an index,
+// "xs[i]", or a slice, "xs[start:end]", where both bounds may be left out.
+func (p *Parser) finishIndex(object Expr) Expr {
+	bracket := p.previous()
+
+	var start Expr
+	if !p.check(COLON) {
+		start = p.expression()
+	}
+
+	if !p.match(COLON) {
+		closing := p.consume(RIGHT_BRACKET, "Expect ']' after index.")
+		return &Index{object, bracket, start, object.Span().Through(closing.Span)}
+	}
+
+	var end Expr
+	if !p.check(RIGHT_BRACKET) {
+		end = p.expression()
+	}
+
+	closing := p.consume(RIGHT_BRACKET, "Expect ']' after slice.")
+	return &Slice{object, bracket, start, end, object.Span().Through(closing.Span)}
+}
+
+func (p *Parser) primary() Expr {
+	if p.match(FALSE) {
+		return &Literal{false, p.previous().Span}
+	}
+
+	if p.match(TRUE) {
+		return &Literal{true, p.previous().Span}
+	}
+
+	if p.match(NIL) {
+		return &Literal{nil, p.previous().Span}
+	}
+
+	if p.match(NUMBER, STRING) {
+		return &Literal{p.previous().Literal, p.previous().Span}
+	}
+
+	if p.match(INTERPOLATION) {
+		return p.interpolation()
+	}
+
+	if p.match(SUPER) {
+		keyword := p.previous()
+		p.consume(DOT, "Expect '.' after 'super'.")
+		method := p.consume(IDENTIFIER, "Expect superclass method name.")
+		return &Super{keyword, method, keyword.Span.Through(method.Span)}
+	}
+
+	if p.match(THIS) {
+		return &This{p.previous(), p.previous().Span}
+	}
+
+	if p.match(IDENTIFIER) {
+		return &VariableExpr{p.previous(), p.previous().Span}
+	}
+
+	if p.match(LEFT_PAREN) {
+		paren := p.previous()
+		expr := p.expression()
+		p.consume(RIGHT_PAREN, "Expect ')' after expression.")
+		return &Grouping{expr, p.spanFrom(paren)}
+	}
+
+	if p.match(LEFT_BRACKET) {
+		bracket := p.previous()
+
+		elements := make([]Expr, 0)
+		for !p.check(RIGHT_BRACKET) {
+			elements = append(elements, p.expression())
+
+			if !p.match(COMMA) {
+				break
+			}
+		}
+
+		p.consume(RIGHT_BRACKET, "Expect ']' after list elements.")
+		return &List{bracket, elements, p.spanFrom(bracket)}
+	}
+
+	if p.match(LEFT_BRACE) {
+		brace := p.previous()
+
+		keys := make([]Expr, 0)
+		values := make([]Expr, 0)
+		for !p.check(RIGHT_BRACE) {
+			keys = append(keys, p.expression())
+			p.consume(COLON, "Expect ':' after map key.")
+			values = append(values, p.expression())
+
+			if !p.match(COMMA) {
+				break
+			}
+		}
+
+		p.consume(RIGHT_BRACE, "Expect '}' after map entries.")
+		return &Map{brace, keys, values, p.spanFrom(brace)}
+	}
+
+	panic(p.error(p.peek(), "Expect expression.", 65))
+}
+
+// interpolation lowers an interpolated string into the concatenation of
+// its text and its stringified expressions, so that "a${b}c" is parsed as
+// "a" + str(b) + "c", where str stands for a ToString node.
+func (p *Parser) interpolation() Expr {
+	start := p.previous()
+
+	var expr Expr = &Literal{start.Literal, start.Span}
+	concat := func(part Token, right Expr) {
+		plus := Token{Type: PLUS, Lexeme: string(PLUS), Line: part.Line, Span: part.Span}
+		expr = &Binary{expr, plus, right, start.Span.Through(right.Span())}
+	}
+
+	for {
+		part := p.previous()
+		value := p.expression()
+		concat(part, &ToString{value, value.Span()})
+
+		// The rest of the string starts with the '}' that closes the
+		// expression, unlike a string inside the expression itself.
+		if !(p.check(INTERPOLATION) || p.check(STRING)) || !strings.HasPrefix(p.peek().Lexeme, "}") {
+			panic(p.error(p.peek(), "Expect '}' after interpolated expression.", 65))
+		}
+
+		text := p.advance()
+		concat(text, &Literal{text.Literal, text.Span})
+
+		if text.Type == STRING {
+			return expr
+		}
+	}
+}
+
+func (p *Parser) match(tokenTypes ...TokenType) bool {
+	for _, tokenType := range tokenTypes {
+		if p.check(tokenType) {
+			p.advance()
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *Parser) check(tokenType TokenType) bool {
+	if p.isAtEnd() {
+		return false
+	}
+
+	return p.peek().Type == tokenType
+}
+
+// checkNext looks one token past the current one.
+func (p *Parser) checkNext(tokenType TokenType) bool {
+	if p.isAtEnd() || p.tokens[p.current+1].Type == EOF {
+		return false
+	}
+
+	return p.tokens[p.current+1].Type == tokenType
+}
+
+func (p *Parser) advance() Token {
+	if !p.isAtEnd() {
+		p.current += 1
+	}
+
+	return p.previous()
+}
+
+func (p *Parser) consume(tokenType TokenType, message string) Token {
+	if p.check(tokenType) {
+		return p.advance()
+	}
+
+	panic(p.error(p.peek(), message, 65))
+}
+
+func (p *Parser) isAtEnd() bool {
+	return p.peek().Type == EOF
+}
+
+func (p *Parser) peek() Token {
+	return p.tokens[p.current]
+}
+
+func (p *Parser) previous() Token {
+	return p.tokens[p.current-1]
+}
+
+// spanFrom returns the span from the start of token to the end of the
+// last token consumed.
+func (p *Parser) spanFrom(token Token) Span {
+	return token.Span.Through(p.previous().Span)
+}
+
+// error records a syntax error. It only panics if the caller panics with
+// the returned parseError, which lets errors that leave the parser in a
+// known state be reported without unwinding.
+func (p *Parser) error(token Token, message string, exitCode int, notes ...string) parseError {
+	p.Lox.errors = append(p.Lox.errors, Error{Type: SyntaxError, Token: token, Message: message, ExitCode: exitCode, Notes: notes})
+	return parseError{}
+}
+
+// synchronize discards tokens until it reaches what is probably the start
+// of the next statement.
+func (p *Parser) synchronize() {
+	p.advance()
+
+	for !p.isAtEnd() {
+		if p.previous().Type == SEMICOLON {
+			return
+		}
+
+		switch p.peek().Type {
+		case CLASS, FUN, VAR, FOR, IF, WHILE, PRINT, RETURN, BREAK, CONTINUE:
+			return
+		}
+
+		p.advance()
+	}
+}
@@ -0,0 +1,454 @@
+package lox
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math"
+)
+
+// A compiled program file starts with a fixed header:
+//
+//	magic    4 bytes  "LOXC"
+//	version  2 bytes  big endian
+//	length   4 bytes  big endian length of the payload
+//	checksum 4 bytes  big endian CRC-32 (IEEE) of the payload
+//
+// The payload holds the source file name followed by the script function.
+// Integers in the payload are unsigned varints and strings are a length
+// followed by their bytes. A function is its name, arity, upvalue count,
+// code, line table, span table and constants; each constant is a tag byte
+// followed by a float64 in big endian, a string or a nested function.
+const (
+	programMagic   = "LOXC"
+	programVersion = 3
+
+	programHeaderSize = 14
+)
+
+const (
+	constantNumber byte = iota + 1
+	constantString
+	constantFunction
+)
+
+var errTruncatedProgram = errors.New("compiled program is truncated")
+
+// Program is a script compiled to bytecode together with the name of the
+// file it was compiled from, ready to be written to disk and run later
+// without scanning or parsing it again.
+type Program struct {
+	SourceName string
+	Script     *CompiledFunction
+}
+
+// IsCompiledProgram reports whether data starts like a compiled program
+// rather than Lox source.
+func IsCompiledProgram(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(programMagic))
+}
+
+// MarshalBinary encodes the program in the compiled program file format.
+func (p *Program) MarshalBinary() ([]byte, error) {
+	var payload bytes.Buffer
+	writeString(&payload, p.SourceName)
+	if err := writeFunction(&payload, p.Script); err != nil {
+		return nil, err
+	}
+
+	var data bytes.Buffer
+	data.WriteString(programMagic)
+	binary.Write(&data, binary.BigEndian, uint16(programVersion))
+	binary.Write(&data, binary.BigEndian, uint32(payload.Len()))
+	binary.Write(&data, binary.BigEndian, crc32.ChecksumIEEE(payload.Bytes()))
+	data.Write(payload.Bytes())
+
+	return data.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a compiled program file, rejecting files written
+// for another format version and files that fail the checksum.
+func (p *Program) UnmarshalBinary(data []byte) error {
+	if !IsCompiledProgram(data) {
+		return errors.New("not a compiled Lox program")
+	}
+
+	if len(data) < programHeaderSize {
+		return errTruncatedProgram
+	}
+
+	version := binary.BigEndian.Uint16(data[4:6])
+	if version != programVersion {
+		return fmt.Errorf("compiled program has format version %d but this interpreter only runs version %d; compile it again from source", version, programVersion)
+	}
+
+	length := binary.BigEndian.Uint32(data[6:10])
+	checksum := binary.BigEndian.Uint32(data[10:14])
+
+	payload := data[programHeaderSize:]
+	if uint64(len(payload)) != uint64(length) {
+		return errTruncatedProgram
+	}
+
+	if crc32.ChecksumIEEE(payload) != checksum {
+		return errors.New("compiled program is corrupt: checksum mismatch")
+	}
+
+	reader := &programReader{data: payload}
+
+	sourceName, err := reader.string()
+	if err != nil {
+		return err
+	}
+
+	script, err := reader.function()
+	if err != nil {
+		return err
+	}
+
+	if reader.offset != len(payload) {
+		return errors.New("compiled program has trailing data")
+	}
+
+	p.SourceName = sourceName
+	p.Script = script
+
+	return nil
+}
+
+func writeUint(buffer *bytes.Buffer, value int) {
+	buffer.Write(binary.AppendUvarint(nil, uint64(value)))
+}
+
+func writeString(buffer *bytes.Buffer, value string) {
+	writeUint(buffer, len(value))
+	buffer.WriteString(value)
+}
+
+func writePosition(buffer *bytes.Buffer, position Position) {
+	writeUint(buffer, position.Offset)
+	writeUint(buffer, position.Line)
+	writeUint(buffer, position.Column)
+}
+
+func writeFunction(buffer *bytes.Buffer, function *CompiledFunction) error {
+	writeString(buffer, function.Name)
+	writeUint(buffer, function.Arity)
+	writeUint(buffer, function.UpvalueCount)
+
+	chunk := function.Chunk
+
+	writeUint(buffer, len(chunk.Code))
+	buffer.Write(chunk.Code)
+
+	writeUint(buffer, len(chunk.Lines))
+	for _, line := range chunk.Lines {
+		writeUint(buffer, line.Offset)
+		writeUint(buffer, line.Line)
+	}
+
+	writeUint(buffer, len(chunk.Spans))
+	for _, span := range chunk.Spans {
+		writeUint(buffer, span.Offset)
+		writePosition(buffer, span.Span.Start)
+		writePosition(buffer, span.Span.End)
+	}
+
+	writeUint(buffer, len(chunk.Constants))
+	for _, constant := range chunk.Constants {
+		switch constant := constant.(type) {
+		case float64:
+			buffer.WriteByte(constantNumber)
+			binary.Write(buffer, binary.BigEndian, math.Float64bits(constant))
+		case string:
+			buffer.WriteByte(constantString)
+			writeString(buffer, constant)
+		case *CompiledFunction:
+			buffer.WriteByte(constantFunction)
+			if err := writeFunction(buffer, constant); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("can't serialize constant of type %T", constant)
+		}
+	}
+
+	return nil
+}
+
+type programReader struct {
+	data   []byte
+	offset int
+}
+
+func (r *programReader) byte() (byte, error) {
+	if r.offset >= len(r.data) {
+		return 0, errTruncatedProgram
+	}
+
+	b := r.data[r.offset]
+	r.offset += 1
+	return b, nil
+}
+
+func (r *programReader) uint() (int, error) {
+	value, n := binary.Uvarint(r.data[r.offset:])
+	if n <= 0 || value > math.MaxInt32 {
+		return 0, errTruncatedProgram
+	}
+
+	r.offset += n
+	return int(value), nil
+}
+
+func (r *programReader) bytes() ([]byte, error) {
+	length, err := r.uint()
+	if err != nil {
+		return nil, err
+	}
+
+	if length > len(r.data)-r.offset {
+		return nil, errTruncatedProgram
+	}
+
+	value := r.data[r.offset : r.offset+length]
+	r.offset += length
+	return value, nil
+}
+
+func (r *programReader) string() (string, error) {
+	value, err := r.bytes()
+	return string(value), err
+}
+
+func (r *programReader) position() (Position, error) {
+	var position Position
+	var err error
+
+	if position.Offset, err = r.uint(); err != nil {
+		return position, err
+	}
+
+	if position.Line, err = r.uint(); err != nil {
+		return position, err
+	}
+
+	position.Column, err = r.uint()
+	return position, err
+}
+
+func (r *programReader) function() (*CompiledFunction, error) {
+	name, err := r.string()
+	if err != nil {
+		return nil, err
+	}
+
+	function := newCompiledFunction(name)
+
+	if function.Arity, err = r.uint(); err != nil {
+		return nil, err
+	}
+
+	if function.UpvalueCount, err = r.uint(); err != nil {
+		return nil, err
+	}
+
+	code, err := r.bytes()
+	if err != nil {
+		return nil, err
+	}
+	function.Chunk.Code = append(function.Chunk.Code, code...)
+
+	lineCount, err := r.uint()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < lineCount; i++ {
+		offset, err := r.uint()
+		if err != nil {
+			return nil, err
+		}
+
+		line, err := r.uint()
+		if err != nil {
+			return nil, err
+		}
+
+		function.Chunk.Lines = append(function.Chunk.Lines, LineStart{Offset: offset, Line: line})
+	}
+
+	spanCount, err := r.uint()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < spanCount; i++ {
+		offset, err := r.uint()
+		if err != nil {
+			return nil, err
+		}
+
+		start, err := r.position()
+		if err != nil {
+			return nil, err
+		}
+
+		end, err := r.position()
+		if err != nil {
+			return nil, err
+		}
+
+		function.Chunk.Spans = append(function.Chunk.Spans, SpanStart{Offset: offset, Span: Span{Start: start, End: end}})
+	}
+
+	constantCount, err := r.uint()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < constantCount; i++ {
+		tag, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+
+		switch tag {
+		case constantNumber:
+			if len(r.data)-r.offset < 8 {
+				return nil, errTruncatedProgram
+			}
+			bits := binary.BigEndian.Uint64(r.data[r.offset:])
+			r.offset += 8
+			function.Chunk.Constants = append(function.Chunk.Constants, math.Float64frombits(bits))
+		case constantString:
+			value, err := r.string()
+			if err != nil {
+				return nil, err
+			}
+			function.Chunk.Constants = append(function.Chunk.Constants, value)
+		case constantFunction:
+			nested, err := r.function()
+			if err != nil {
+				return nil, err
+			}
+			function.Chunk.Constants = append(function.Chunk.Constants, nested)
+		default:
+			return nil, fmt.Errorf("compiled program has unknown constant tag %d", tag)
+		}
+	}
+
+	if err := verifyFunction(function); err != nil {
+		return nil, fmt.Errorf("compiled program is invalid: %s: %w", function, err)
+	}
+
+	return function, nil
+}
+
+// verifyFunction checks that every instruction is complete and refers to
+// constants of the right type, to upvalues the function has and to jump
+// targets inside its chunk.
+func verifyFunction(function *CompiledFunction) error {
+	chunk := function.Chunk
+	code := chunk.Code
+
+	constantOperand := func(offset int) (Value, error) {
+		if offset+2 > len(code) {
+			return nil, errors.New("instruction is cut short")
+		}
+
+		index := readShortOperand(chunk, offset)
+		if index >= len(chunk.Constants) {
+			return nil, fmt.Errorf("constant %d is out of range", index)
+		}
+
+		return chunk.Constants[index], nil
+	}
+
+	for offset := 0; offset < len(code); {
+		op := OpCode(code[offset])
+
+		switch op {
+		case OP_CONSTANT:
+			constant, err := constantOperand(offset + 1)
+			if err != nil {
+				return err
+			}
+			if _, ok := constant.(*CompiledFunction); ok {
+				return fmt.Errorf("%s at %d loads a function", op, offset)
+			}
+			offset += 3
+		case OP_GET_GLOBAL, OP_DEFINE_GLOBAL, OP_SET_GLOBAL, OP_GET_PROPERTY,
+			OP_SET_PROPERTY, OP_GET_SUPER, OP_CLASS, OP_METHOD:
+			constant, err := constantOperand(offset + 1)
+			if err != nil {
+				return err
+			}
+			if _, ok := constant.(string); !ok {
+				return fmt.Errorf("%s at %d needs a name", op, offset)
+			}
+			offset += 3
+		case OP_CALL:
+			if offset+2 > len(code) {
+				return errors.New("instruction is cut short")
+			}
+			offset += 2
+		case OP_GET_LOCAL, OP_SET_LOCAL, OP_BUILD_LIST, OP_BUILD_MAP:
+			if offset+3 > len(code) {
+				return errors.New("instruction is cut short")
+			}
+			offset += 3
+		case OP_GET_UPVALUE, OP_SET_UPVALUE:
+			if offset+3 > len(code) {
+				return errors.New("instruction is cut short")
+			}
+			if readShortOperand(chunk, offset+1) >= function.UpvalueCount {
+				return fmt.Errorf("%s at %d refers to a missing upvalue", op, offset)
+			}
+			offset += 3
+		case OP_JUMP, OP_JUMP_IF_FALSE, OP_LOOP:
+			if offset+3 > len(code) {
+				return errors.New("instruction is cut short")
+			}
+
+			target := offset + 3 + readShortOperand(chunk, offset+1)
+			if op == OP_LOOP {
+				target = offset + 3 - readShortOperand(chunk, offset+1)
+			}
+			if target < 0 || target > len(code) {
+				return fmt.Errorf("%s at %d jumps out of the chunk", op, offset)
+			}
+			offset += 3
+		case OP_CLOSURE:
+			constant, err := constantOperand(offset + 1)
+			if err != nil {
+				return err
+			}
+
+			nested, ok := constant.(*CompiledFunction)
+			if !ok {
+				return fmt.Errorf("%s at %d needs a function", op, offset)
+			}
+
+			offset += 3
+			for i := 0; i < nested.UpvalueCount; i++ {
+				if offset+3 > len(code) {
+					return errors.New("instruction is cut short")
+				}
+				if code[offset] == 0 && readShortOperand(chunk, offset+1) >= function.UpvalueCount {
+					return fmt.Errorf("%s captures a missing upvalue", op)
+				}
+				offset += 3
+			}
+		default:
+			if _, ok := opCodeNames[op]; !ok {
+				return fmt.Errorf("unknown opcode %d at %d", op, offset)
+			}
+			offset += 1
+		}
+	}
+
+	return nil
+}
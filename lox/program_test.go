@@ -0,0 +1,152 @@
+package lox_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/michalzarsm/lox-interpreter/lox"
+)
+
+const programSource = `
+class Greeter {
+  init(greeting) { this.greeting = greeting; }
+  greet(name) { return this.greeting + ", " + name + "!"; }
+}
+
+fun counter() {
+  var n = 0;
+  fun next() { n = n + 1; return n; }
+  return next;
+}
+
+var next = counter();
+next();
+print Greeter("Hello").greet("lox") + " " + "${next()}";
+print [1.5, "two", nil, true];
+`
+
+func compileProgram(t *testing.T) *lox.Program {
+	t.Helper()
+
+	script, err := lox.Compile(programSource)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+
+	return &lox.Program{SourceName: "greeter.lox", Script: script}
+}
+
+func marshalProgram(t *testing.T, program *lox.Program) []byte {
+	t.Helper()
+
+	data, err := program.MarshalBinary()
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	return data
+}
+
+func TestProgramRoundTrip(t *testing.T) {
+	program := compileProgram(t)
+	data := marshalProgram(t, program)
+
+	if !lox.IsCompiledProgram(data) {
+		t.Fatal("the encoded program isn't recognized as one")
+	}
+
+	loaded := &lox.Program{}
+	if err := loaded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if loaded.SourceName != program.SourceName {
+		t.Errorf("source name is %q, want %q", loaded.SourceName, program.SourceName)
+	}
+
+	var want, got bytes.Buffer
+	lox.Disassemble(&want, program.Script)
+	lox.Disassemble(&got, loaded.Script)
+	if got.String() != want.String() {
+		t.Errorf("loaded bytecode differs\ngot:\n%s\nwant:\n%s", got.String(), want.String())
+	}
+
+	if again := marshalProgram(t, loaded); !bytes.Equal(again, data) {
+		t.Error("encoding the loaded program gives different bytes")
+	}
+
+	var stdout bytes.Buffer
+	runtime := lox.NewRuntime()
+	runtime.Stdout = &stdout
+	if err := runtime.RunProgram(loaded); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	if output := "Hello, lox! 2\n[1.5, \"two\", nil, true]\n"; stdout.String() != output {
+		t.Errorf("output is %q, want %q", stdout.String(), output)
+	}
+}
+
+func TestProgramRejectsDamagedFiles(t *testing.T) {
+	data := marshalProgram(t, compileProgram(t))
+
+	tests := []struct {
+		name   string
+		damage func([]byte) []byte
+		want   string
+	}{
+		{
+			name: "version mismatch",
+			damage: func(data []byte) []byte {
+				binary.BigEndian.PutUint16(data[4:6], 1)
+				return data
+			},
+			want: "format version 1",
+		},
+		{
+			name: "bad checksum",
+			damage: func(data []byte) []byte {
+				data[len(data)-1] ^= 0xff
+				return data
+			},
+			want: "checksum mismatch",
+		},
+		{
+			name: "truncated payload",
+			damage: func(data []byte) []byte {
+				return data[:len(data)-1]
+			},
+			want: "truncated",
+		},
+		{
+			name: "truncated header",
+			damage: func(data []byte) []byte {
+				return data[:8]
+			},
+			want: "truncated",
+		},
+		{
+			name: "not a program",
+			damage: func(data []byte) []byte {
+				return []byte("print 1;")
+			},
+			want: "not a compiled Lox program",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			damaged := test.damage(append([]byte(nil), data...))
+
+			err := (&lox.Program{}).UnmarshalBinary(damaged)
+			if err == nil {
+				t.Fatal("the damaged program was accepted")
+			}
+			if !strings.Contains(err.Error(), test.want) {
+				t.Errorf("error %q doesn't mention %q", err.Error(), test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package lox
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// RunPrompt reads Lox from input line by line and runs it on the runtime,
+// so definitions carry over from one entry to the next. Entries with
+// unbalanced brackets or an unterminated string are continued on the next
+// line before being run. The value of an entry ending in an expression statement, or
+// of an entry that is a bare expression without a ';', is printed; errors
+// are reported to Stderr and don't end the session.
+func (r *Runtime) RunPrompt(input io.Reader) {
+	reader := bufio.NewScanner(input)
+	source := ""
+
+	for {
+		if source == "" {
+			fmt.Fprint(r.Stdout, "> ")
+		} else {
+			fmt.Fprint(r.Stdout, "... ")
+		}
+
+		if !reader.Scan() {
+			fmt.Fprintln(r.Stdout)
+			return
+		}
+
+		if source != "" {
+			source += "\n"
+		}
+		source += reader.Text()
+
+		if !isComplete(source) {
+			continue
+		}
+
+		r.runEntry(source)
+		source = ""
+	}
+}
+
+func (r *Runtime) runEntry(source string) {
+	statements, err := r.prepareEntry(source)
+	if err != nil {
+		fmt.Fprintln(r.Stderr, err.Error())
+		return
+	}
+
+	value, err := r.execute(statements)
+	if err != nil {
+		fmt.Fprintln(r.Stderr, err.Error())
+		return
+	}
+
+	if len(statements) == 0 {
+		return
+	}
+
+	if _, ok := statements[len(statements)-1].(*Expression); ok {
+		fmt.Fprintln(r.Stdout, stringify(value))
+	}
+}
+
+// prepareEntry parses and resolves an entry as statements or, failing
+// that, as a single expression, which is then run as an expression
+// statement. The errors reported are those of the statements unless the
+// entry does parse as an expression.
+func (r *Runtime) prepareEntry(source string) ([]Stmt, error) {
+	statements, err := r.prepare(source)
+	if err == nil {
+		return statements, nil
+	}
+
+	lox := newLox()
+	scanner := newScanner(source, lox)
+	scanner.scanTokens()
+
+	expr := newParser(scanner.Tokens, lox).parseExpression()
+	if expr == nil || lox.failed() != nil {
+		return nil, err
+	}
+
+	statements = []Stmt{&Expression{expr, expr.Span()}}
+	return statements, r.resolve(statements)
+}
+
+// isComplete reports whether every brace, parenthesis and bracket opened
+// in the source has been closed, and no string or "${" is left open.
+func isComplete(source string) bool {
+	scanner := newScanner(source, newLox())
+	scanner.scanTokens()
+	if scanner.unfinished {
+		return false
+	}
+
+	depth := 0
+	for _, token := range scanner.Tokens {
+		switch token.Type {
+		case LEFT_BRACE, LEFT_PAREN, LEFT_BRACKET:
+			depth += 1
+		case RIGHT_BRACE, RIGHT_PAREN, RIGHT_BRACKET:
+			depth -= 1
+		}
+	}
+
+	return depth <= 0
+}
@@ -0,0 +1,68 @@
+package lox_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/michalzarsm/lox-interpreter/lox"
+)
+
+func TestRunPrompt(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		stdout string
+		stderr string
+	}{
+		{
+			name:   "multi-line string",
+			input:  "print \"a\nb\";\n",
+			stdout: "> ... a\nb\n> \n",
+		},
+		{
+			name:   "multi-line string expression",
+			input:  "\"one\ntwo\"\n",
+			stdout: "> ... one\ntwo\n> \n",
+		},
+		{
+			name:   "raw string",
+			input:  "print `a\n${1 + 1}`;\n",
+			stdout: "> ... a\n${1 + 1}\n> \n",
+		},
+		{
+			name:   "open interpolation",
+			input:  "print \"v ${\n1 + 1} end\";\n",
+			stdout: "> ... v 2 end\n> \n",
+		},
+		{
+			name:   "open bracket",
+			input:  "var xs = [1,\n2];\nxs\n",
+			stdout: "> ... > [1, 2]\n> \n",
+		},
+		{
+			name:   "error on the entry's line",
+			input:  "var a = 1;\na +\n",
+			stdout: "> > > \n",
+			stderr: "[line 1] Error at end: Expect expression.\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			runtime := lox.NewRuntime()
+			runtime.Stdout = &stdout
+			runtime.Stderr = &stderr
+			runtime.RunPrompt(strings.NewReader(test.input))
+
+			if stdout.String() != test.stdout {
+				t.Errorf("stdout is %q, want %q", stdout.String(), test.stdout)
+			}
+			if stderr.String() != test.stderr {
+				t.Errorf("stderr is %q, want %q", stderr.String(), test.stderr)
+			}
+		})
+	}
+}
@@ -0,0 +1,451 @@
+package lox
+
+type FunctionType int
+
+const (
+	FunctionTypeNone FunctionType = iota
+	FunctionTypeFunction
+	FunctionTypeInitializer
+	FunctionTypeMethod
+)
+
+type ClassType int
+
+const (
+	ClassTypeNone ClassType = iota
+	ClassTypeClass
+	ClassTypeSubclass
+)
+
+// Resolver walks the AST once before it is run and tells the interpreter
+// how far up the environment chain each local variable lives.
+type Resolver struct {
+	Lox             *Lox
+	interpreter     *Interpreter
+	scopes          []map[string]bool
+	currentFunction FunctionType
+	currentClass    ClassType
+
+	// symbols collects declarations and their uses for tooling. It is nil
+	// when the program is only going to be run.
+	symbols *symbolTable
+}
+
+func newResolver(interpreter *Interpreter, lox *Lox) *Resolver {
+	return &Resolver{
+		Lox:             lox,
+		interpreter:     interpreter,
+		scopes:          make([]map[string]bool, 0),
+		currentFunction: FunctionTypeNone,
+		currentClass:    ClassTypeNone,
+	}
+}
+
+func (r *Resolver) resolve(statements []Stmt) {
+	for _, statement := range statements {
+		r.resolveStmt(statement)
+	}
+}
+
+func (r *Resolver) resolveStmt(stmt Stmt) {
+	stmt.Accept(r)
+}
+
+func (r *Resolver) resolveExpr(expr Expr) {
+	expr.Accept(r)
+}
+
+func (r *Resolver) resolveFunction(function *Function, functionType FunctionType, symbol *Symbol) {
+	enclosingFunction := r.currentFunction
+	r.currentFunction = functionType
+
+	r.enterSymbol(symbol)
+	r.beginScope()
+	for _, param := range function.Params {
+		r.declare(param)
+		r.define(param)
+		r.declareSymbol(param, SymbolParameter, function)
+	}
+	r.resolve(function.Body)
+	r.endScope()
+	r.leaveSymbol()
+
+	r.currentFunction = enclosingFunction
+}
+
+func (r *Resolver) resolveLocal(expr Expr, name Token) {
+	for i := len(r.scopes) - 1; i >= 0; i-- {
+		if _, ok := r.scopes[i][name.Lexeme]; ok {
+			r.interpreter.resolve(expr, len(r.scopes)-1-i)
+			return
+		}
+	}
+}
+
+func (r *Resolver) beginScope() {
+	r.scopes = append(r.scopes, make(map[string]bool))
+	if r.symbols != nil {
+		r.symbols.beginScope()
+	}
+}
+
+func (r *Resolver) endScope() {
+	r.scopes = r.scopes[:len(r.scopes)-1]
+	if r.symbols != nil {
+		r.symbols.endScope()
+	}
+}
+
+func (r *Resolver) declareSymbol(name Token, kind SymbolKind, declaration Stmt) *Symbol {
+	if r.symbols == nil {
+		return nil
+	}
+
+	return r.symbols.declare(name, kind, declaration)
+}
+
+func (r *Resolver) referenceSymbol(name Token) {
+	if r.symbols != nil {
+		r.symbols.reference(name)
+	}
+}
+
+func (r *Resolver) enterSymbol(symbol *Symbol) {
+	if r.symbols != nil {
+		r.symbols.enter(symbol)
+	}
+}
+
+func (r *Resolver) leaveSymbol() {
+	if r.symbols != nil {
+		r.symbols.leave()
+	}
+}
+
+func (r *Resolver) declare(name Token) {
+	if len(r.scopes) == 0 {
+		return
+	}
+
+	scope := r.scopes[len(r.scopes)-1]
+	if _, ok := scope[name.Lexeme]; ok {
+		r.error(name, "Already a variable with this name in this scope.")
+	}
+
+	scope[name.Lexeme] = false
+}
+
+func (r *Resolver) define(name Token) {
+	if len(r.scopes) == 0 {
+		return
+	}
+
+	r.scopes[len(r.scopes)-1][name.Lexeme] = true
+}
+
+func (r *Resolver) error(token Token, message string) {
+	r.Lox.errors = append(r.Lox.errors, Error{Type: SyntaxError, Token: token, Message: message, ExitCode: 65})
+}
+
+func (r *Resolver) VisitBlockStmt(stmt *Block) any {
+	r.beginScope()
+	r.resolve(stmt.Statements)
+	r.endScope()
+
+	return nil
+}
+
+func (r *Resolver) VisitBreakStmt(stmt *Break) any {
+	return nil
+}
+
+func (r *Resolver) VisitContinueStmt(stmt *Continue) any {
+	return nil
+}
+
+func (r *Resolver) VisitClassStmt(stmt *Class) any {
+	enclosingClass := r.currentClass
+	r.currentClass = ClassTypeClass
+
+	r.declare(stmt.Name)
+	r.define(stmt.Name)
+	symbol := r.declareSymbol(stmt.Name, SymbolClass, stmt)
+
+	if stmt.Superclass != nil {
+		superclass := stmt.Superclass.(*VariableExpr)
+		if stmt.Name.Lexeme == superclass.Name.Lexeme {
+			r.error(superclass.Name, "A class can't inherit from itself.")
+		}
+
+		r.currentClass = ClassTypeSubclass
+		r.resolveExpr(stmt.Superclass)
+
+		r.beginScope()
+		r.scopes[len(r.scopes)-1]["super"] = true
+	}
+
+	r.beginScope()
+	r.scopes[len(r.scopes)-1]["this"] = true
+
+	r.enterSymbol(symbol)
+	for _, method := range stmt.Methods {
+		declaration := FunctionTypeMethod
+		if method.Name.Lexeme == "init" {
+			declaration = FunctionTypeInitializer
+		}
+
+		var methodSymbol *Symbol
+		if r.symbols != nil {
+			methodSymbol = r.symbols.add(method.Name, SymbolMethod, method)
+		}
+
+		r.resolveFunction(method, declaration, methodSymbol)
+	}
+	r.leaveSymbol()
+
+	r.endScope()
+
+	if stmt.Superclass != nil {
+		r.endScope()
+	}
+
+	r.currentClass = enclosingClass
+
+	return nil
+}
+
+func (r *Resolver) VisitExpressionStmt(stmt *Expression) any {
+	r.resolveExpr(stmt.Expression)
+	return nil
+}
+
+func (r *Resolver) VisitFunctionStmt(stmt *Function) any {
+	r.declare(stmt.Name)
+	r.define(stmt.Name)
+	symbol := r.declareSymbol(stmt.Name, SymbolFunction, stmt)
+
+	r.resolveFunction(stmt, FunctionTypeFunction, symbol)
+
+	return nil
+}
+
+func (r *Resolver) VisitIfStmt(stmt *If) any {
+	r.resolveExpr(stmt.Condition)
+	r.resolveStmt(stmt.ThenBranch)
+	if stmt.ElseBranch != nil {
+		r.resolveStmt(stmt.ElseBranch)
+	}
+
+	return nil
+}
+
+func (r *Resolver) VisitPrintStmt(stmt *Print) any {
+	r.resolveExpr(stmt.Expression)
+	return nil
+}
+
+func (r *Resolver) VisitReturnStmt(stmt *Return) any {
+	if r.currentFunction == FunctionTypeNone {
+		r.error(stmt.Keyword, "Can't return from top-level code.")
+	}
+
+	if stmt.Value != nil {
+		if r.currentFunction == FunctionTypeInitializer {
+			r.error(stmt.Keyword, "Can't return a value from an initializer.")
+		}
+
+		r.resolveExpr(stmt.Value)
+	}
+
+	return nil
+}
+
+func (r *Resolver) VisitVariableStmtStmt(stmt *VariableStmt) any {
+	r.declare(stmt.Name)
+	if stmt.Initializer != nil {
+		r.resolveExpr(stmt.Initializer)
+	}
+	r.define(stmt.Name)
+	r.declareSymbol(stmt.Name, SymbolVariable, stmt)
+
+	return nil
+}
+
+func (r *Resolver) VisitForStmt(stmt *For) any {
+	r.beginScope()
+
+	if stmt.Initializer != nil {
+		r.resolveStmt(stmt.Initializer)
+	}
+
+	if stmt.Condition != nil {
+		r.resolveExpr(stmt.Condition)
+	}
+
+	if stmt.Increment != nil {
+		r.resolveExpr(stmt.Increment)
+	}
+
+	r.resolveStmt(stmt.Body)
+	r.endScope()
+
+	return nil
+}
+
+func (r *Resolver) VisitWhileStmt(stmt *While) any {
+	r.resolveExpr(stmt.Condition)
+	r.resolveStmt(stmt.Body)
+
+	return nil
+}
+
+func (r *Resolver) VisitAssignExpr(expr *Assign) any {
+	r.resolveExpr(expr.value)
+	r.resolveLocal(expr, expr.Name)
+	r.referenceSymbol(expr.Name)
+
+	return nil
+}
+
+func (r *Resolver) VisitTernaryExpr(expr *Ternary) any {
+	r.resolveExpr(expr.Condition)
+	r.resolveExpr(expr.TrueExpr)
+	r.resolveExpr(expr.FalseExpr)
+
+	return nil
+}
+
+func (r *Resolver) VisitBinaryExpr(expr *Binary) any {
+	r.resolveExpr(expr.Left)
+	r.resolveExpr(expr.Right)
+
+	return nil
+}
+
+func (r *Resolver) VisitCallExpr(expr *Call) any {
+	r.resolveExpr(expr.Callee)
+
+	for _, argument := range expr.Arguments {
+		r.resolveExpr(argument)
+	}
+
+	return nil
+}
+
+func (r *Resolver) VisitGetExpr(expr *Get) any {
+	r.resolveExpr(expr.Object)
+	return nil
+}
+
+func (r *Resolver) VisitGroupingExpr(expr *Grouping) any {
+	r.resolveExpr(expr.Expression)
+	return nil
+}
+
+func (r *Resolver) VisitIndexExpr(expr *Index) any {
+	r.resolveExpr(expr.Object)
+	r.resolveExpr(expr.Index)
+
+	return nil
+}
+
+func (r *Resolver) VisitListExpr(expr *List) any {
+	for _, element := range expr.Elements {
+		r.resolveExpr(element)
+	}
+
+	return nil
+}
+
+func (r *Resolver) VisitLiteralExpr(expr *Literal) any {
+	return nil
+}
+
+func (r *Resolver) VisitLogicalExpr(expr *Logical) any {
+	r.resolveExpr(expr.Left)
+	r.resolveExpr(expr.Right)
+
+	return nil
+}
+
+func (r *Resolver) VisitSetExpr(expr *Set) any {
+	r.resolveExpr(expr.Value)
+	r.resolveExpr(expr.Object)
+
+	return nil
+}
+
+func (r *Resolver) VisitMapExpr(expr *Map) any {
+	for i, key := range expr.Keys {
+		r.resolveExpr(key)
+		r.resolveExpr(expr.Values[i])
+	}
+
+	return nil
+}
+
+func (r *Resolver) VisitSetIndexExpr(expr *SetIndex) any {
+	r.resolveExpr(expr.Object)
+	r.resolveExpr(expr.Index)
+	r.resolveExpr(expr.Value)
+
+	return nil
+}
+
+func (r *Resolver) VisitSliceExpr(expr *Slice) any {
+	r.resolveExpr(expr.Object)
+	if expr.Start != nil {
+		r.resolveExpr(expr.Start)
+	}
+	if expr.End != nil {
+		r.resolveExpr(expr.End)
+	}
+
+	return nil
+}
+
+func (r *Resolver) VisitToStringExpr(expr *ToString) any {
+	r.resolveExpr(expr.Expression)
+	return nil
+}
+
+func (r *Resolver) VisitSuperExpr(expr *Super) any {
+	if r.currentClass == ClassTypeNone {
+		r.error(expr.Keyword, "Can't use 'super' outside of a class.")
+	} else if r.currentClass != ClassTypeSubclass {
+		r.error(expr.Keyword, "Can't use 'super' in a class with no superclass.")
+	}
+
+	r.resolveLocal(expr, expr.Keyword)
+
+	return nil
+}
+
+func (r *Resolver) VisitThisExpr(expr *This) any {
+	if r.currentClass == ClassTypeNone {
+		r.error(expr.Keyword, "Can't use 'this' outside of a class.")
+		return nil
+	}
+
+	r.resolveLocal(expr, expr.Keyword)
+
+	return nil
+}
+
+func (r *Resolver) VisitUnaryExpr(expr *Unary) any {
+	r.resolveExpr(expr.Right)
+	return nil
+}
+
+func (r *Resolver) VisitVariableExprExpr(expr *VariableExpr) any {
+	if len(r.scopes) > 0 {
+		if defined, ok := r.scopes[len(r.scopes)-1][expr.Name.Lexeme]; ok && !defined {
+			r.error(expr.Name, "Can't read local variable in its own initializer.")
+		}
+	}
+
+	r.resolveLocal(expr, expr.Name)
+	r.referenceSymbol(expr.Name)
+
+	return nil
+}
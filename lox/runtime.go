@@ -0,0 +1,209 @@
+package lox
+
+import (
+	"io"
+	"os"
+)
+
+// Value is anything a Lox expression can evaluate to: nil, bool, float64,
+// string, or one of the runtime types such as *LoxFunction, *LoxClass,
+// *LoxInstance, *LoxList and *LoxMap.
+type Value = any
+
+// Backend selects how a Runtime executes programs.
+type Backend string
+
+const (
+	// BackendTree walks the syntax tree directly.
+	BackendTree Backend = "tree"
+	// BackendVM compiles to bytecode and runs it on a stack machine.
+	BackendVM Backend = "vm"
+)
+
+// Runtime is an embeddable Lox interpreter. Globals defined by one call to
+// Eval or Run stay visible to later calls on the same Runtime.
+type Runtime struct {
+	// Stdout receives the output of print statements.
+	Stdout io.Writer
+	// Stderr receives the errors reported by RunPrompt.
+	Stderr io.Writer
+	// Backend is the execution engine. Both give the same results, but it
+	// should be chosen before the first program runs, as classes created
+	// by one can't be used by the other.
+	Backend Backend
+	// Debugger, if set, is stepped before every statement. Programs then
+	// always run on the tree-walking backend.
+	Debugger Debugger
+
+	globals     *Environment
+	interpreter *Interpreter
+	vm          *VM
+}
+
+func NewRuntime() *Runtime {
+	globals := newEnvironment(nil)
+	defineGlobals(globals)
+
+	return &Runtime{
+		Stdout:      os.Stdout,
+		Stderr:      os.Stderr,
+		Backend:     BackendTree,
+		globals:     globals,
+		interpreter: newInterpreter(globals, os.Stdout),
+		vm:          newVM(globals, os.Stdout),
+	}
+}
+
+// Globals returns the global environment, where host functions can be
+// registered with DefineNative and DefineFunc.
+func (r *Runtime) Globals() *Environment {
+	return r.globals
+}
+
+// Eval runs the source and returns the value of its last statement if
+// that is an expression statement, or nil otherwise. Any error is of type
+// Errors.
+func (r *Runtime) Eval(source string) (Value, error) {
+	statements, err := r.prepare(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.execute(statements)
+}
+
+// Run reads a whole program from reader and runs it. The program may be
+// Lox source or a compiled Program; compiled programs always run on the VM
+// backend.
+func (r *Runtime) Run(reader io.Reader) error {
+	source, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	if IsCompiledProgram(source) {
+		program := &Program{}
+		if err := program.UnmarshalBinary(source); err != nil {
+			return err
+		}
+
+		return r.RunProgram(program)
+	}
+
+	_, err = r.Eval(string(source))
+	return err
+}
+
+// RunProgram runs a compiled program on the VM backend.
+func (r *Runtime) RunProgram(program *Program) error {
+	r.vm.stdout = r.Stdout
+
+	_, err := r.vm.interpret(program.Script)
+	if err != nil {
+		return Errors{err.(Error)}
+	}
+
+	return nil
+}
+
+// prepare parses the source and resolves it against this runtime's
+// interpreter.
+func (r *Runtime) prepare(source string) ([]Stmt, error) {
+	statements, err := Parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	return statements, r.resolve(statements)
+}
+
+// resolve resolves statements against this runtime's interpreter.
+func (r *Runtime) resolve(statements []Stmt) error {
+	lox := newLox()
+	resolver := newResolver(r.interpreter, lox)
+	resolver.resolve(statements)
+
+	return lox.failed()
+}
+
+func (r *Runtime) execute(statements []Stmt) (Value, error) {
+	if r.Backend == BackendVM && r.Debugger == nil {
+		return r.executeBytecode(statements)
+	}
+
+	r.interpreter.stdout = r.Stdout
+	r.interpreter.debugger = r.Debugger
+
+	value, err := r.interpreter.interpretValue(statements)
+	if err != nil {
+		return nil, Errors{err.(Error)}
+	}
+
+	return value, nil
+}
+
+func (r *Runtime) executeBytecode(statements []Stmt) (Value, error) {
+	function, err := compileStatements(statements)
+	if err != nil {
+		return nil, err
+	}
+
+	r.vm.stdout = r.Stdout
+
+	value, err := r.vm.interpret(function)
+	if err != nil {
+		return nil, Errors{err.(Error)}
+	}
+
+	return value, nil
+}
+
+// Compile checks the source and compiles it to bytecode for the VM
+// backend, returning the function for its top-level script.
+func Compile(source string) (*CompiledFunction, error) {
+	statements, err := Parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	lox := newLox()
+	resolver := newResolver(newInterpreter(newEnvironment(nil), io.Discard), lox)
+	resolver.resolve(statements)
+	if err := lox.failed(); err != nil {
+		return nil, err
+	}
+
+	return compileStatements(statements)
+}
+
+func compileStatements(statements []Stmt) (*CompiledFunction, error) {
+	lox := newLox()
+	compiler := newCompiler(lox)
+	function := compiler.compile(statements)
+
+	return function, lox.failed()
+}
+
+// Tokenize scans the source. The tokens are returned even when there are
+// errors, so they can be shown alongside them.
+func Tokenize(source string) ([]Token, error) {
+	lox := newLox()
+	scanner := newScanner(source, lox)
+	scanner.scanTokens()
+
+	return scanner.Tokens, lox.failed()
+}
+
+// Parse scans and parses the source into statements. The parser runs even
+// when the scanner reports errors, so that the error holds every syntax
+// error in the source.
+func Parse(source string) ([]Stmt, error) {
+	lox := newLox()
+	scanner := newScanner(source, lox)
+	scanner.scanTokens()
+
+	parser := newParser(scanner.Tokens, lox)
+	statements := parser.parse()
+
+	return statements, lox.failed()
+}
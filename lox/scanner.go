@@ -0,0 +1,512 @@
+package lox
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type Scanner struct {
+	Lox     *Lox
+	Source  string
+	Tokens  []Token
+	Start   Position
+	Current int
+	Line    int
+
+	// LineStart is the offset of the first byte of the current line.
+	LineStart int
+
+	// Comments holds the "//" comments in source order. They are not part
+	// of Tokens, so the parser never sees them.
+	Comments []Token
+
+	// interpolations holds the "${" of every interpolated expression being
+	// scanned, innermost last.
+	interpolations []interpolation
+
+	// unfinished is set when the source ends inside a string or an
+	// interpolated expression, all of which may span lines.
+	unfinished bool
+}
+
+// interpolation tracks an expression inside "${...}". braces counts the
+// '{' opened in the expression itself, so that only the '}' matching the
+// "${" resumes the string.
+type interpolation struct {
+	open   Span
+	braces int
+}
+
+func newScanner(source string, lox *Lox) *Scanner {
+	return &Scanner{
+		Lox:      lox,
+		Source:   source,
+		Tokens:   make([]Token, 0),
+		Comments: make([]Token, 0),
+		Start:    Position{Offset: 0, Line: 1, Column: 1},
+		Current:  0,
+		Line:     1,
+	}
+}
+
+func (s *Scanner) scanTokens() {
+	for s.Current < len(s.Source) {
+		s.Start = s.position()
+		s.scanToken()
+	}
+
+	s.unfinished = s.unfinished || len(s.interpolations) > 0
+	for _, interpolation := range s.interpolations {
+		s.errorAt(interpolation.open, SyntaxError, "Unterminated string interpolation.", "The '${' here is never closed with a '}'.")
+	}
+
+	s.Start = s.position()
+	s.Tokens = append(s.Tokens, Token{Type: EOF, Line: s.Line, Span: s.span()})
+}
+
+// position returns the position the scanner has reached.
+func (s *Scanner) position() Position {
+	return Position{Offset: s.Current, Line: s.Line, Column: s.Current - s.LineStart + 1}
+}
+
+// span returns the span of the token being scanned.
+func (s *Scanner) span() Span {
+	return Span{Start: s.Start, End: s.position()}
+}
+
+// newline records that the scanner just consumed a line break.
+func (s *Scanner) newline() {
+	s.Line += 1
+	s.LineStart = s.Current
+}
+
+func (s *Scanner) error(errorType ErrorType, message string, notes ...string) {
+	s.errorAt(s.span(), errorType, message, notes...)
+}
+
+// errorAt reports an error at a span inside the token being scanned, such
+// as an escape sequence in a string.
+func (s *Scanner) errorAt(span Span, errorType ErrorType, message string, notes ...string) {
+	s.Lox.errors = append(s.Lox.errors, Error{Type: errorType, Token: Token{Line: span.Start.Line, Span: span}, Message: message, ExitCode: 65, Notes: notes})
+}
+
+func (s *Scanner) scanToken() {
+	c := s.advance()
+	switch TokenType(c) {
+	case LEFT_PAREN:
+		s.addToken(Token{Type: LEFT_PAREN, Lexeme: string(LEFT_PAREN), Literal: nil, Line: s.Line})
+	case RIGHT_PAREN:
+		s.addToken(Token{Type: RIGHT_PAREN, Lexeme: string(RIGHT_PAREN), Literal: nil, Line: s.Line})
+	case LEFT_BRACE:
+		if len(s.interpolations) > 0 {
+			s.interpolations[len(s.interpolations)-1].braces += 1
+		}
+		s.addToken(Token{Type: LEFT_BRACE, Lexeme: string(LEFT_BRACE), Literal: nil, Line: s.Line})
+	case RIGHT_BRACE:
+		if len(s.interpolations) > 0 {
+			open := &s.interpolations[len(s.interpolations)-1]
+			if open.braces == 0 {
+				s.interpolations = s.interpolations[:len(s.interpolations)-1]
+				s.string()
+				return
+			}
+			open.braces -= 1
+		}
+		s.addToken(Token{Type: RIGHT_BRACE, Lexeme: string(RIGHT_BRACE), Literal: nil, Line: s.Line})
+	case LEFT_BRACKET:
+		s.addToken(Token{Type: LEFT_BRACKET, Lexeme: string(LEFT_BRACKET), Literal: nil, Line: s.Line})
+	case RIGHT_BRACKET:
+		s.addToken(Token{Type: RIGHT_BRACKET, Lexeme: string(RIGHT_BRACKET), Literal: nil, Line: s.Line})
+	case COLON:
+		s.addToken(Token{Type: COLON, Lexeme: string(COLON), Literal: nil, Line: s.Line})
+	case COMMA:
+		s.addToken(Token{Type: COMMA, Lexeme: string(COMMA), Literal: nil, Line: s.Line})
+	case DOT:
+		s.addToken(Token{Type: DOT, Lexeme: string(DOT), Literal: nil, Line: s.Line})
+	case MINUS:
+		s.addToken(Token{Type: MINUS, Lexeme: string(MINUS), Literal: nil, Line: s.Line})
+	case PLUS:
+		s.addToken(Token{Type: PLUS, Lexeme: string(PLUS), Literal: nil, Line: s.Line})
+	case QUESTION:
+		s.addToken(Token{Type: QUESTION, Lexeme: string(QUESTION), Literal: nil, Line: s.Line})
+	case SEMICOLON:
+		s.addToken(Token{Type: SEMICOLON, Lexeme: string(SEMICOLON), Literal: nil, Line: s.Line})
+	case SLASH:
+		if s.match('/') {
+			for s.peek() != '\n' && !s.isAtEnd() {
+				s.advance()
+			}
+
+			comment := strings.TrimRight(s.Source[s.Start.Offset:s.Current], "\r")
+			s.Comments = append(s.Comments, Token{Type: COMMENT, Lexeme: comment, Line: s.Line, Span: s.span()})
+		} else {
+			s.addToken(Token{Type: SLASH, Lexeme: string(SLASH), Literal: nil, Line: s.Line})
+		}
+	case STAR:
+		s.addToken(Token{Type: STAR, Lexeme: string(STAR), Literal: nil, Line: s.Line})
+	case "!":
+		if s.match('=') {
+			s.addToken(Token{Type: BANG_EQUAL, Lexeme: string(BANG_EQUAL), Literal: nil, Line: s.Line})
+
+		} else {
+			s.addToken(Token{Type: BANG, Lexeme: string(BANG), Literal: nil, Line: s.Line})
+		}
+	case "=":
+		if s.match('=') {
+			s.addToken(Token{Type: EQUAL_EQUAL, Lexeme: string(EQUAL_EQUAL), Literal: nil, Line: s.Line})
+		} else {
+			s.addToken(Token{Type: EQUAL, Lexeme: string(EQUAL), Literal: nil, Line: s.Line})
+		}
+	case "<":
+		if s.match('=') {
+			s.addToken(Token{Type: LESS_EQUAL, Lexeme: string(LESS_EQUAL), Literal: nil, Line: s.Line})
+		} else {
+			s.addToken(Token{Type: LESS, Lexeme: string(LESS), Literal: nil, Line: s.Line})
+		}
+	case ">":
+		if s.match('=') {
+			s.addToken(Token{Type: GREATER_EQUAL, Lexeme: string(GREATER_EQUAL), Literal: nil, Line: s.Line})
+		} else {
+			s.addToken(Token{Type: GREATER, Lexeme: string(GREATER), Literal: nil, Line: s.Line})
+		}
+	case " ":
+		{
+		}
+	case "\r":
+		{
+		}
+	case "\t":
+		{
+			break
+		}
+	case "\n":
+		{
+			s.newline()
+			break
+		}
+	case "\"":
+		{
+			s.string()
+		}
+	case "`":
+		{
+			s.rawString()
+		}
+	default:
+		if isDigit(c) {
+			s.number()
+		} else if isAlpha(c) {
+			s.identifier()
+		} else {
+			s.error(SyntaxError, fmt.Sprintf("Unexpected character: %c", c))
+		}
+	}
+}
+
+// string scans a string from just after its opening '"', or from just
+// after the '}' that closes an interpolated expression, up to the closing
+// '"' or the next "${". The lexeme is the source text, quotes and escape
+// sequences included, and the literal the text the string stands for.
+func (s *Scanner) string() {
+	var value strings.Builder
+
+	for s.peek() != '"' && !s.isAtEnd() {
+		if s.peek() == '$' && s.peekNext() == '{' {
+			s.advance()
+			s.advance()
+
+			s.addToken(Token{Type: INTERPOLATION, Lexeme: s.Source[s.Start.Offset:s.Current], Literal: value.String(), Line: s.Line})
+			s.interpolations = append(s.interpolations, interpolation{open: s.span()})
+			return
+		}
+
+		switch c := s.advance(); c {
+		case '\\':
+			s.escape(&value)
+		case '\n':
+			s.newline()
+			value.WriteByte(c)
+		default:
+			value.WriteByte(c)
+		}
+	}
+
+	if s.isAtEnd() {
+		s.unfinished = true
+		s.error(SyntaxError, "Unterminated string.", "The string starts here and runs to the end of the file; add a closing '\"'.")
+		return
+	}
+
+	s.advance()
+
+	s.addToken(Token{Type: STRING, Lexeme: s.Source[s.Start.Offset:s.Current], Literal: value.String(), Line: s.Line})
+}
+
+// escape reads the escape sequence after a '\' and writes the character it
+// stands for to value. An invalid sequence is reported where it appears
+// and left out of the string.
+func (s *Scanner) escape(value *strings.Builder) {
+	start := Position{Offset: s.Current - 1, Line: s.Line, Column: s.Current - s.LineStart}
+
+	if s.isAtEnd() {
+		return
+	}
+
+	c := s.advance()
+	switch c {
+	case 'n':
+		value.WriteByte('\n')
+	case 't':
+		value.WriteByte('\t')
+	case 'r':
+		value.WriteByte('\r')
+	case '"', '\\', '$':
+		value.WriteByte(c)
+	case 'u':
+		s.unicodeEscape(value, start)
+	case '\r', '\n':
+		if c == '\n' {
+			s.newline()
+		}
+
+		backslash := Span{Start: start, End: Position{Offset: start.Offset + 1, Line: start.Line, Column: start.Column + 1}}
+		s.errorAt(backslash, SyntaxError, "Invalid escape sequence at end of line.", "Strings can span lines without one; write '\\\\' for a backslash.")
+	default:
+		// Take in the whole character if it is more than one byte long.
+		_, size := utf8.DecodeRuneInString(s.Source[s.Current-1:])
+		s.Current += size - 1
+
+		sequence := s.Source[start.Offset:s.Current]
+		s.errorAt(Span{Start: start, End: s.position()}, SyntaxError, fmt.Sprintf("Invalid escape sequence '%s'.", sequence), "Valid escapes are \\n, \\t, \\r, \\\", \\\\, \\$ and \\u{...}.")
+	}
+}
+
+// unicodeEscape reads the "{...}" of a "\u{...}" escape, holding the code
+// point in hexadecimal, and writes the character as UTF-8.
+func (s *Scanner) unicodeEscape(value *strings.Builder, start Position) {
+	if !s.match('{') {
+		s.errorAt(Span{Start: start, End: s.position()}, SyntaxError, "Invalid unicode escape.", "Write the code point in hexadecimal between braces, as in \\u{1F600}.")
+		return
+	}
+
+	digitStart := s.Current
+	for isHexDigit(s.peek()) {
+		s.advance()
+	}
+	digits := s.Source[digitStart:s.Current]
+
+	if !s.match('}') || len(digits) == 0 || len(digits) > 6 {
+		s.errorAt(Span{Start: start, End: s.position()}, SyntaxError, "Invalid unicode escape.", "Write the code point as 1 to 6 hexadecimal digits between braces, as in \\u{1F600}.")
+		return
+	}
+
+	codePoint, _ := strconv.ParseUint(digits, 16, 32)
+	if codePoint > unicode.MaxRune || (codePoint >= 0xD800 && codePoint <= 0xDFFF) {
+		s.errorAt(Span{Start: start, End: s.position()}, SyntaxError, fmt.Sprintf("'%s' is not a valid unicode code point.", s.Source[start.Offset:s.Current]))
+		return
+	}
+
+	value.WriteRune(rune(codePoint))
+}
+
+// rawString scans a string between backticks. Everything up to the closing
+// backtick is taken as it is, line breaks included, without escapes or
+// interpolation.
+func (s *Scanner) rawString() {
+	for s.peek() != '`' && !s.isAtEnd() {
+		if s.advance() == '\n' {
+			s.newline()
+		}
+	}
+
+	if s.isAtEnd() {
+		s.unfinished = true
+		s.error(SyntaxError, "Unterminated raw string.", "The string starts here and runs to the end of the file; add a closing '`'.")
+		return
+	}
+
+	s.advance()
+
+	lexeme := s.Source[s.Start.Offset:s.Current]
+	s.addToken(Token{Type: STRING, Lexeme: lexeme, Literal: lexeme[1 : len(lexeme)-1], Line: s.Line})
+}
+
+func (s *Scanner) number() {
+	digitStart := s.Current - 1
+
+	for isDigit(s.peek()) {
+		s.advance()
+	}
+
+	if s.peek() == '.' && isDigit(s.peekNext()) {
+		s.advance()
+
+		for isDigit(s.peek()) {
+			s.advance()
+		}
+	}
+
+	value := s.Source[digitStart:s.Current]
+
+	parsedValue, err := strconv.ParseFloat(value, 64)
+
+	if err != nil {
+		s.error(ValueConvertError, "Float Parse Error.")
+		return
+	}
+
+	s.addToken(Token{Type: NUMBER, Lexeme: value, Literal: parsedValue, Line: s.Line})
+}
+
+func (s *Scanner) identifier() {
+	identifierStart := s.Current
+	for isAlphaNumeric(s.peek()) {
+		s.advance()
+	}
+
+	value := s.Source[identifierStart-1 : s.Current]
+
+	switch TokenType(value) {
+	case AND:
+		{
+			s.addToken(Token{Type: AND, Lexeme: value, Literal: nil, Line: s.Line})
+		}
+	case BREAK:
+		{
+			s.addToken(Token{Type: BREAK, Lexeme: value, Literal: nil, Line: s.Line})
+		}
+	case CLASS:
+		{
+			s.addToken(Token{Type: CLASS, Lexeme: value, Literal: nil, Line: s.Line})
+		}
+	case CONTINUE:
+		{
+			s.addToken(Token{Type: CONTINUE, Lexeme: value, Literal: nil, Line: s.Line})
+		}
+	case ELSE:
+		{
+			s.addToken(Token{Type: ELSE, Lexeme: value, Literal: nil, Line: s.Line})
+		}
+	case FALSE:
+		{
+			s.addToken(Token{Type: FALSE, Lexeme: value, Literal: nil, Line: s.Line})
+		}
+	case FOR:
+		{
+			s.addToken(Token{Type: FOR, Lexeme: value, Literal: nil, Line: s.Line})
+		}
+	case FUN:
+		{
+			s.addToken(Token{Type: FUN, Lexeme: value, Literal: nil, Line: s.Line})
+		}
+	case IF:
+		{
+			s.addToken(Token{Type: IF, Lexeme: value, Literal: nil, Line: s.Line})
+		}
+	case NIL:
+		{
+			s.addToken(Token{Type: NIL, Lexeme: value, Literal: nil, Line: s.Line})
+		}
+	case OR:
+		{
+			s.addToken(Token{Type: OR, Lexeme: value, Literal: nil, Line: s.Line})
+		}
+	case PRINT:
+		{
+			s.addToken(Token{Type: PRINT, Lexeme: value, Literal: nil, Line: s.Line})
+		}
+	case RETURN:
+		{
+			s.addToken(Token{Type: RETURN, Lexeme: value, Literal: nil, Line: s.Line})
+		}
+	case SUPER:
+		{
+			s.addToken(Token{Type: SUPER, Lexeme: value, Literal: nil, Line: s.Line})
+		}
+	case THIS:
+		{
+			s.addToken(Token{Type: THIS, Lexeme: value, Literal: nil, Line: s.Line})
+		}
+	case TRUE:
+		{
+			s.addToken(Token{Type: TRUE, Lexeme: value, Literal: nil, Line: s.Line})
+		}
+	case VAR:
+		{
+			s.addToken(Token{Type: VAR, Lexeme: value, Literal: nil, Line: s.Line})
+		}
+	case WHILE:
+		{
+			s.addToken(Token{Type: WHILE, Lexeme: value, Literal: nil, Line: s.Line})
+		}
+	default:
+		{
+			s.addToken(Token{Type: IDENTIFIER, Lexeme: value, Literal: nil, Line: s.Line})
+		}
+	}
+
+}
+
+func isAlpha(c byte) bool {
+	return (c >= 'a' && c <= 'z') ||
+		(c >= 'A' && c <= 'Z') ||
+		c == '_'
+}
+
+func isAlphaNumeric(c byte) bool {
+	return isAlpha(c) || isDigit(c)
+}
+
+func (s *Scanner) match(expected byte) bool {
+	if s.isAtEnd() {
+		return false
+	}
+	if s.Source[s.Current] != expected {
+		return false
+	}
+
+	s.Current += 1
+	return true
+}
+
+func (s *Scanner) peek() byte {
+	if s.isAtEnd() {
+		return 0
+	}
+
+	return s.Source[s.Current]
+}
+
+func (s *Scanner) peekNext() byte {
+	if s.Current+1 >= len(s.Source) {
+		return 0
+	}
+
+	return s.Source[s.Current+1]
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func (s *Scanner) addToken(token Token) {
+	token.Span = s.span()
+	s.Tokens = append(s.Tokens, token)
+}
+
+func (s *Scanner) isAtEnd() bool {
+	return s.Current >= len(s.Source)
+}
+
+func (s *Scanner) advance() byte {
+	c := s.Source[s.Current]
+	s.Current += 1
+	return c
+}
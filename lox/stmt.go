@@ -0,0 +1,194 @@
+package lox
+
+type Stmt interface {
+	Accept(visitor StmtVisitor) any
+	Span() Span
+}
+
+type StmtVisitor interface {
+	VisitBlockStmt(block *Block) any
+	VisitBreakStmt(breakStmt *Break) any
+	VisitClassStmt(class *Class) any
+	VisitContinueStmt(continueStmt *Continue) any
+	VisitExpressionStmt(expression *Expression) any
+	VisitForStmt(forStmt *For) any
+	VisitFunctionStmt(function *Function) any
+	VisitIfStmt(ifStmt *If) any
+	VisitPrintStmt(print *Print) any
+	VisitReturnStmt(returnStmt *Return) any
+	VisitVariableStmtStmt(variablestmt *VariableStmt) any
+	VisitWhileStmt(while *While) any
+}
+
+type Block struct {
+	Statements []Stmt
+	span Span
+}
+
+func (thisBlock *Block) Accept(visitor StmtVisitor) any {
+	return visitor.VisitBlockStmt(thisBlock)
+}
+
+func (thisBlock *Block) Span() Span {
+	return thisBlock.span
+}
+
+type Break struct {
+	Keyword Token
+	Label Token
+	span Span
+}
+
+func (thisBreak *Break) Accept(visitor StmtVisitor) any {
+	return visitor.VisitBreakStmt(thisBreak)
+}
+
+func (thisBreak *Break) Span() Span {
+	return thisBreak.span
+}
+
+type Class struct {
+	Name Token
+	Superclass Expr
+	Methods []*Function
+	span Span
+}
+
+func (thisClass *Class) Accept(visitor StmtVisitor) any {
+	return visitor.VisitClassStmt(thisClass)
+}
+
+func (thisClass *Class) Span() Span {
+	return thisClass.span
+}
+
+type Continue struct {
+	Keyword Token
+	Label Token
+	span Span
+}
+
+func (thisContinue *Continue) Accept(visitor StmtVisitor) any {
+	return visitor.VisitContinueStmt(thisContinue)
+}
+
+func (thisContinue *Continue) Span() Span {
+	return thisContinue.span
+}
+
+type Expression struct {
+	Expression Expr
+	span Span
+}
+
+func (thisExpression *Expression) Accept(visitor StmtVisitor) any {
+	return visitor.VisitExpressionStmt(thisExpression)
+}
+
+func (thisExpression *Expression) Span() Span {
+	return thisExpression.span
+}
+
+type For struct {
+	Initializer Stmt
+	Condition Expr
+	Increment Expr
+	Body Stmt
+	Label Token
+	span Span
+}
+
+func (thisFor *For) Accept(visitor StmtVisitor) any {
+	return visitor.VisitForStmt(thisFor)
+}
+
+func (thisFor *For) Span() Span {
+	return thisFor.span
+}
+
+type Function struct {
+	Name Token
+	Params []Token
+	Body []Stmt
+	span Span
+}
+
+func (thisFunction *Function) Accept(visitor StmtVisitor) any {
+	return visitor.VisitFunctionStmt(thisFunction)
+}
+
+func (thisFunction *Function) Span() Span {
+	return thisFunction.span
+}
+
+type If struct {
+	Condition Expr
+	ThenBranch Stmt
+	ElseBranch Stmt
+	span Span
+}
+
+func (thisIf *If) Accept(visitor StmtVisitor) any {
+	return visitor.VisitIfStmt(thisIf)
+}
+
+func (thisIf *If) Span() Span {
+	return thisIf.span
+}
+
+type Print struct {
+	Expression Expr
+	span Span
+}
+
+func (thisPrint *Print) Accept(visitor StmtVisitor) any {
+	return visitor.VisitPrintStmt(thisPrint)
+}
+
+func (thisPrint *Print) Span() Span {
+	return thisPrint.span
+}
+
+type Return struct {
+	Keyword Token
+	Value Expr
+	span Span
+}
+
+func (thisReturn *Return) Accept(visitor StmtVisitor) any {
+	return visitor.VisitReturnStmt(thisReturn)
+}
+
+func (thisReturn *Return) Span() Span {
+	return thisReturn.span
+}
+
+type VariableStmt struct {
+	Name Token
+	Initializer Expr
+	span Span
+}
+
+func (thisVariableStmt *VariableStmt) Accept(visitor StmtVisitor) any {
+	return visitor.VisitVariableStmtStmt(thisVariableStmt)
+}
+
+func (thisVariableStmt *VariableStmt) Span() Span {
+	return thisVariableStmt.span
+}
+
+type While struct {
+	Condition Expr
+	Body Stmt
+	Label Token
+	span Span
+}
+
+func (thisWhile *While) Accept(visitor StmtVisitor) any {
+	return visitor.VisitWhileStmt(thisWhile)
+}
+
+func (thisWhile *While) Span() Span {
+	return thisWhile.span
+}
+
@@ -0,0 +1,169 @@
+package lox
+
+import "strings"
+
+type SymbolKind string
+
+const (
+	SymbolVariable  SymbolKind = "variable"
+	SymbolParameter SymbolKind = "parameter"
+	SymbolFunction  SymbolKind = "function"
+	SymbolMethod    SymbolKind = "method"
+	SymbolClass     SymbolKind = "class"
+)
+
+// Symbol is a name declared in a program, together with every use of it
+// that the Resolver tied back to the declaration.
+type Symbol struct {
+	Name Token
+	Kind SymbolKind
+
+	// Declaration is the statement that declares the symbol. For a
+	// parameter it is the function the parameter belongs to.
+	Declaration Stmt
+
+	// Parent is the function or class the symbol is declared in, or nil
+	// for a symbol declared at the top level.
+	Parent *Symbol
+
+	// Shadows is the symbol of the same name in an enclosing scope that
+	// this one hides, if any.
+	Shadows *Symbol
+
+	References []Token
+}
+
+// Signature describes the declaration of the symbol the way it is written
+// in source, without any bodies.
+func (s *Symbol) Signature() string {
+	switch s.Kind {
+	case SymbolFunction, SymbolMethod:
+		function := s.Declaration.(*Function)
+
+		params := make([]string, 0, len(function.Params))
+		for _, param := range function.Params {
+			params = append(params, param.Lexeme)
+		}
+
+		signature := s.Name.Lexeme + "(" + strings.Join(params, ", ") + ")"
+		if s.Kind == SymbolFunction {
+			return "fun " + signature
+		}
+
+		return signature
+	case SymbolClass:
+		class := s.Declaration.(*Class)
+		if class.Superclass != nil {
+			return "class " + s.Name.Lexeme + " < " + class.Superclass.(*VariableExpr).Name.Lexeme
+		}
+
+		return "class " + s.Name.Lexeme
+	case SymbolParameter:
+		return "parameter " + s.Name.Lexeme
+	}
+
+	return "var " + s.Name.Lexeme
+}
+
+// symbolTable collects symbols while the Resolver walks a program. Its
+// scopes mirror the Resolver's, and globals, which the Resolver doesn't
+// track, live in a map of their own. A global may be used in a function
+// before it is declared, so uses that don't match anything are settled
+// once the whole program has been seen.
+type symbolTable struct {
+	symbols    []*Symbol
+	scopes     []map[string]*Symbol
+	globals    map[string]*Symbol
+	parents    []*Symbol
+	unresolved []Token
+}
+
+func newSymbolTable() *symbolTable {
+	return &symbolTable{
+		symbols: make([]*Symbol, 0),
+		scopes:  make([]map[string]*Symbol, 0),
+		globals: make(map[string]*Symbol),
+		parents: make([]*Symbol, 0),
+	}
+}
+
+func (t *symbolTable) beginScope() {
+	t.scopes = append(t.scopes, make(map[string]*Symbol))
+}
+
+func (t *symbolTable) endScope() {
+	t.scopes = t.scopes[:len(t.scopes)-1]
+}
+
+// add records a symbol without binding its name in any scope, as for
+// methods, which are only ever looked up on instances.
+func (t *symbolTable) add(name Token, kind SymbolKind, declaration Stmt) *Symbol {
+	symbol := &Symbol{Name: name, Kind: kind, Declaration: declaration, References: make([]Token, 0)}
+	if len(t.parents) > 0 {
+		symbol.Parent = t.parents[len(t.parents)-1]
+	}
+
+	t.symbols = append(t.symbols, symbol)
+	return symbol
+}
+
+// declare records a symbol and binds its name in the innermost scope.
+func (t *symbolTable) declare(name Token, kind SymbolKind, declaration Stmt) *Symbol {
+	symbol := t.add(name, kind, declaration)
+	symbol.Shadows = t.lookup(name.Lexeme)
+
+	if len(t.scopes) == 0 {
+		t.globals[name.Lexeme] = symbol
+	} else {
+		t.scopes[len(t.scopes)-1][name.Lexeme] = symbol
+	}
+
+	return symbol
+}
+
+// lookup finds the symbol a new declaration in the innermost scope would
+// hide, looking through the enclosing scopes and then the globals.
+func (t *symbolTable) lookup(name string) *Symbol {
+	if len(t.scopes) == 0 {
+		return nil
+	}
+
+	for i := len(t.scopes) - 2; i >= 0; i-- {
+		if symbol, ok := t.scopes[i][name]; ok {
+			return symbol
+		}
+	}
+
+	return t.globals[name]
+}
+
+func (t *symbolTable) reference(name Token) {
+	for i := len(t.scopes) - 1; i >= 0; i-- {
+		if symbol, ok := t.scopes[i][name.Lexeme]; ok {
+			symbol.References = append(symbol.References, name)
+			return
+		}
+	}
+
+	t.unresolved = append(t.unresolved, name)
+}
+
+func (t *symbolTable) enter(symbol *Symbol) {
+	t.parents = append(t.parents, symbol)
+}
+
+func (t *symbolTable) leave() {
+	t.parents = t.parents[:len(t.parents)-1]
+}
+
+// finish ties the remaining uses to globals. Uses of names that are never
+// declared, such as native functions, are dropped.
+func (t *symbolTable) finish() {
+	for _, name := range t.unresolved {
+		if symbol, ok := t.globals[name.Lexeme]; ok {
+			symbol.References = append(symbol.References, name)
+		}
+	}
+
+	t.unresolved = nil
+}
@@ -0,0 +1,152 @@
+package lox
+
+type TokenType string
+
+const (
+	// Single-character tokens.
+	LEFT_PAREN    TokenType = "("
+	RIGHT_PAREN   TokenType = ")"
+	LEFT_BRACE    TokenType = "{"
+	RIGHT_BRACE   TokenType = "}"
+	LEFT_BRACKET  TokenType = "["
+	RIGHT_BRACKET TokenType = "]"
+	COLON         TokenType = ":"
+	COMMA         TokenType = ","
+	DOT           TokenType = "."
+	MINUS         TokenType = "-"
+	PLUS          TokenType = "+"
+	QUESTION      TokenType = "?"
+	SEMICOLON     TokenType = ";"
+	SLASH         TokenType = "/"
+	STAR          TokenType = "*"
+
+	// One or two character tokens.
+	BANG          TokenType = "!"
+	BANG_EQUAL    TokenType = "!="
+	EQUAL         TokenType = "="
+	EQUAL_EQUAL   TokenType = "=="
+	GREATER       TokenType = ">"
+	GREATER_EQUAL TokenType = ">="
+	LESS          TokenType = "<"
+	LESS_EQUAL    TokenType = "<="
+
+	// Literals.
+	IDENTIFIER TokenType = "IDENTIFIER"
+	STRING     TokenType = "STRING"
+	NUMBER     TokenType = "NUMBER"
+
+	// INTERPOLATION is the part of a string up to a "${". The expression
+	// follows as ordinary tokens, and the rest of the string, from the
+	// closing '}', is another INTERPOLATION or, at the end, a STRING.
+	INTERPOLATION TokenType = "INTERPOLATION"
+
+	// Keywords.
+	AND      TokenType = "and"
+	BREAK    TokenType = "break"
+	CLASS    TokenType = "class"
+	CONTINUE TokenType = "continue"
+	ELSE     TokenType = "else"
+	FALSE    TokenType = "false"
+	FUN      TokenType = "fun"
+	FOR      TokenType = "for"
+	IF       TokenType = "if"
+	NIL      TokenType = "nil"
+	OR       TokenType = "or"
+	PRINT    TokenType = "print"
+	RETURN   TokenType = "return"
+	SUPER    TokenType = "super"
+	THIS     TokenType = "this"
+	TRUE     TokenType = "true"
+	VAR      TokenType = "var"
+	WHILE    TokenType = "while"
+
+	// Trivia. Comments are kept apart from the tokens the parser sees.
+	COMMENT TokenType = "COMMENT"
+
+	EOF TokenType = "eof"
+)
+
+// Position is a place in the source. Offset counts bytes from the start of
+// the source, Line and Column count from 1 and Column is measured in bytes.
+type Position struct {
+	Offset int `json:"offset"`
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Span is the range of source from Start up to, but not including, End.
+type Span struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Through returns the span from the start of s to the end of end.
+func (s Span) Through(end Span) Span {
+	return Span{Start: s.Start, End: end.End}
+}
+
+type Token struct {
+	Type    TokenType
+	Lexeme  string
+	Literal any
+	Line    int
+	Span    Span
+}
+
+var valueToTokenType = map[string]string{
+	"(":             "LEFT_PAREN",
+	")":             "RIGHT_PAREN",
+	"{":             "LEFT_BRACE",
+	"}":             "RIGHT_BRACE",
+	"[":             "LEFT_BRACKET",
+	"]":             "RIGHT_BRACKET",
+	":":             "COLON",
+	",":             "COMMA",
+	".":             "DOT",
+	"-":             "MINUS",
+	"+":             "PLUS",
+	"?":             "QUESTION",
+	";":             "SEMICOLON",
+	"/":             "SLASH",
+	"*":             "STAR",
+	"!":             "BANG",
+	"!=":            "BANG_EQUAL",
+	"=":             "EQUAL",
+	"==":            "EQUAL_EQUAL",
+	">":             "GREATER",
+	">=":            "GREATER_EQUAL",
+	"<":             "LESS",
+	"<=":            "LESS_EQUAL",
+	"IDENTIFIER":    "IDENTIFIER",
+	"STRING":        "STRING",
+	"NUMBER":        "NUMBER",
+	"INTERPOLATION": "INTERPOLATION",
+	"and":           "AND",
+	"break":         "BREAK",
+	"class":         "CLASS",
+	"continue":      "CONTINUE",
+	"else":          "ELSE",
+	"false":         "FALSE",
+	"fun":           "FUN",
+	"for":           "FOR",
+	"if":            "IF",
+	"nil":           "NIL",
+	"or":            "OR",
+	"print":         "PRINT",
+	"return":        "RETURN",
+	"super":         "SUPER",
+	"this":          "THIS",
+	"true":          "TRUE",
+	"var":           "VAR",
+	"while":         "WHILE",
+	"eof":           "EOF",
+}
+
+// TokenTypeName returns the upper-case name of a token type, as printed by
+// the tokenize command.
+func TokenTypeName(value string) string {
+	if name, ok := valueToTokenType[value]; ok {
+		return name
+	}
+	return "IDENTIFIER"
+}
@@ -0,0 +1,567 @@
+package lox
+
+import (
+	"fmt"
+	"io"
+)
+
+// framesMax bounds the depth of calls the VM allows before reporting a
+// stack overflow.
+const framesMax = 4096
+
+// CompiledFunction is a function compiled to bytecode. The top-level code
+// of a program is compiled to a CompiledFunction without a name.
+type CompiledFunction struct {
+	Name         string
+	Arity        int
+	UpvalueCount int
+	Chunk        *Chunk
+}
+
+func newCompiledFunction(name string) *CompiledFunction {
+	return &CompiledFunction{
+		Name:  name,
+		Chunk: newChunk(),
+	}
+}
+
+func (f *CompiledFunction) String() string {
+	if f.Name == "" {
+		return "<script>"
+	}
+
+	return "<fn " + f.Name + ">"
+}
+
+// Upvalue is a variable captured by a closure. While the variable is still
+// on the stack the upvalue refers to its slot; once the variable goes out
+// of scope the value moves into the upvalue itself.
+type Upvalue struct {
+	slot   int
+	closed Value
+	next   *Upvalue
+}
+
+type Closure struct {
+	function *CompiledFunction
+	upvalues []*Upvalue
+}
+
+func newClosure(function *CompiledFunction) *Closure {
+	return &Closure{
+		function: function,
+		upvalues: make([]*Upvalue, function.UpvalueCount),
+	}
+}
+
+func (c *Closure) String() string {
+	return c.function.String()
+}
+
+// BoundMethod is a method closure together with the instance it was
+// accessed on.
+type BoundMethod struct {
+	receiver Value
+	method   *Closure
+}
+
+func (b *BoundMethod) String() string {
+	return b.method.String()
+}
+
+type callFrame struct {
+	closure *Closure
+	ip      int
+	slots   int
+}
+
+// VM runs bytecode produced by the Compiler. It shares its globals with
+// the tree-walking Interpreter so native functions work with both.
+type VM struct {
+	frames       []callFrame
+	stack        []Value
+	globals      *Environment
+	openUpvalues *Upvalue
+	stdout       io.Writer
+}
+
+func newVM(globals *Environment, stdout io.Writer) *VM {
+	return &VM{
+		frames:  make([]callFrame, 0, framesMax),
+		stack:   make([]Value, 0, 256),
+		globals: globals,
+		stdout:  stdout,
+	}
+}
+
+// interpret runs the compiled script and returns the value it returns.
+func (vm *VM) interpret(function *CompiledFunction) (Value, error) {
+	closure := newClosure(function)
+	vm.push(closure)
+
+	err := vm.call(closure, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return vm.run()
+}
+
+func (vm *VM) push(value Value) {
+	vm.stack = append(vm.stack, value)
+}
+
+func (vm *VM) pop() Value {
+	value := vm.stack[len(vm.stack)-1]
+	vm.stack = vm.stack[:len(vm.stack)-1]
+	return value
+}
+
+func (vm *VM) peek(distance int) Value {
+	return vm.stack[len(vm.stack)-1-distance]
+}
+
+func (vm *VM) resetStack() {
+	vm.stack = vm.stack[:0]
+	vm.frames = vm.frames[:0]
+	vm.openUpvalues = nil
+}
+
+// runtimeError builds the error for the instruction currently executing
+// and clears the VM so it can be used again.
+func (vm *VM) runtimeError(format string, args ...any) error {
+	frame := &vm.frames[len(vm.frames)-1]
+	chunk := frame.closure.function.Chunk
+	token := Token{Line: chunk.getLine(frame.ip - 1), Span: chunk.getSpan(frame.ip - 1)}
+
+	vm.resetStack()
+
+	return newRuntimeError(token, fmt.Sprintf(format, args...))
+}
+
+func (vm *VM) call(closure *Closure, argCount int) error {
+	if argCount != closure.function.Arity {
+		return vm.runtimeError("Expected %d arguments but got %d.", closure.function.Arity, argCount)
+	}
+
+	if len(vm.frames) == framesMax {
+		return vm.runtimeError("Stack overflow.")
+	}
+
+	vm.frames = append(vm.frames, callFrame{
+		closure: closure,
+		ip:      0,
+		slots:   len(vm.stack) - argCount - 1,
+	})
+
+	return nil
+}
+
+func (vm *VM) callValue(callee Value, argCount int) error {
+	switch callee := callee.(type) {
+	case *BoundMethod:
+		vm.stack[len(vm.stack)-argCount-1] = callee.receiver
+		return vm.call(callee.method, argCount)
+	case *LoxClass:
+		vm.stack[len(vm.stack)-argCount-1] = newLoxInstance(callee)
+
+		if initializer, ok := callee.closures["init"]; ok {
+			return vm.call(initializer, argCount)
+		}
+
+		if argCount != 0 {
+			return vm.runtimeError("Expected 0 arguments but got %d.", argCount)
+		}
+
+		return nil
+	case *Closure:
+		return vm.call(callee, argCount)
+	case *NativeFunction:
+		if argCount != callee.arity() {
+			return vm.runtimeError("Expected %d arguments but got %d.", callee.arity(), argCount)
+		}
+
+		arguments := make([]Value, argCount)
+		copy(arguments, vm.stack[len(vm.stack)-argCount:])
+
+		result, err := callee.function(arguments)
+		if err != nil {
+			return vm.runtimeError("%s", err.Error())
+		}
+
+		vm.stack = vm.stack[:len(vm.stack)-argCount-1]
+		vm.push(result)
+
+		return nil
+	}
+
+	return vm.runtimeError("Can only call functions and classes.")
+}
+
+func (vm *VM) bindMethod(class *LoxClass, name string) (*BoundMethod, bool) {
+	method, ok := class.closures[name]
+	if !ok {
+		return nil, false
+	}
+
+	return &BoundMethod{receiver: vm.peek(0), method: method}, true
+}
+
+func (vm *VM) captureUpvalue(slot int) *Upvalue {
+	var previous *Upvalue
+	upvalue := vm.openUpvalues
+
+	for upvalue != nil && upvalue.slot > slot {
+		previous = upvalue
+		upvalue = upvalue.next
+	}
+
+	if upvalue != nil && upvalue.slot == slot {
+		return upvalue
+	}
+
+	created := &Upvalue{slot: slot, next: upvalue}
+	if previous == nil {
+		vm.openUpvalues = created
+	} else {
+		previous.next = created
+	}
+
+	return created
+}
+
+// closeUpvalues moves every variable captured from slot last or above
+// off the stack and into its upvalue.
+func (vm *VM) closeUpvalues(last int) {
+	for vm.openUpvalues != nil && vm.openUpvalues.slot >= last {
+		upvalue := vm.openUpvalues
+		upvalue.closed = vm.stack[upvalue.slot]
+		upvalue.slot = -1
+		vm.openUpvalues = upvalue.next
+	}
+}
+
+func (vm *VM) readUpvalue(upvalue *Upvalue) Value {
+	if upvalue.slot >= 0 {
+		return vm.stack[upvalue.slot]
+	}
+
+	return upvalue.closed
+}
+
+func (vm *VM) writeUpvalue(upvalue *Upvalue, value Value) {
+	if upvalue.slot >= 0 {
+		vm.stack[upvalue.slot] = value
+	} else {
+		upvalue.closed = value
+	}
+}
+
+func (vm *VM) run() (Value, error) {
+	frame := &vm.frames[len(vm.frames)-1]
+	code := frame.closure.function.Chunk.Code
+	constants := frame.closure.function.Chunk.Constants
+
+	readByte := func() byte {
+		b := code[frame.ip]
+		frame.ip += 1
+		return b
+	}
+
+	readShort := func() int {
+		frame.ip += 2
+		return int(code[frame.ip-2])<<8 | int(code[frame.ip-1])
+	}
+
+	readString := func() string {
+		return constants[readShort()].(string)
+	}
+
+	// loadFrame switches to the innermost frame after a call or return.
+	loadFrame := func() {
+		frame = &vm.frames[len(vm.frames)-1]
+		code = frame.closure.function.Chunk.Code
+		constants = frame.closure.function.Chunk.Constants
+	}
+
+	for {
+		op := OpCode(readByte())
+
+		switch op {
+		case OP_CONSTANT:
+			vm.push(constants[readShort()])
+		case OP_NIL:
+			vm.push(nil)
+		case OP_TRUE:
+			vm.push(true)
+		case OP_FALSE:
+			vm.push(false)
+		case OP_POP:
+			vm.pop()
+		case OP_GET_LOCAL:
+			vm.push(vm.stack[frame.slots+readShort()])
+		case OP_SET_LOCAL:
+			vm.stack[frame.slots+readShort()] = vm.peek(0)
+		case OP_GET_GLOBAL:
+			name := readString()
+			value, ok := vm.globals.values[name]
+			if !ok {
+				return nil, vm.runtimeError("Undefined variable '%s'.", name)
+			}
+			vm.push(value)
+		case OP_DEFINE_GLOBAL:
+			vm.globals.define(readString(), vm.pop())
+		case OP_SET_GLOBAL:
+			name := readString()
+			if _, ok := vm.globals.values[name]; !ok {
+				return nil, vm.runtimeError("Undefined variable '%s'.", name)
+			}
+			vm.globals.values[name] = vm.peek(0)
+		case OP_GET_UPVALUE:
+			vm.push(vm.readUpvalue(frame.closure.upvalues[readShort()]))
+		case OP_SET_UPVALUE:
+			vm.writeUpvalue(frame.closure.upvalues[readShort()], vm.peek(0))
+		case OP_GET_PROPERTY:
+			name := readString()
+
+			// Lists and maps only have their native methods.
+			var native Value
+			var err error
+			switch object := vm.peek(0).(type) {
+			case *LoxList:
+				native, err = object.get(Token{Lexeme: name})
+			case *LoxMap:
+				native, err = object.get(Token{Lexeme: name})
+			}
+
+			if err != nil {
+				return nil, vm.runtimeError("%s", err.Error())
+			}
+			if native != nil {
+				vm.stack[len(vm.stack)-1] = native
+				break
+			}
+
+			instance, ok := vm.peek(0).(*LoxInstance)
+			if !ok {
+				return nil, vm.runtimeError("Only instances have properties.")
+			}
+
+			if value, ok := instance.fields[name]; ok {
+				vm.stack[len(vm.stack)-1] = value
+				break
+			}
+
+			method, ok := vm.bindMethod(instance.class, name)
+			if !ok {
+				return nil, vm.runtimeError("Undefined property '%s'.", name)
+			}
+			vm.stack[len(vm.stack)-1] = method
+		case OP_SET_PROPERTY:
+			name := readString()
+			instance, ok := vm.peek(1).(*LoxInstance)
+			if !ok {
+				return nil, vm.runtimeError("Only instances have fields.")
+			}
+
+			value := vm.pop()
+			instance.fields[name] = value
+			vm.stack[len(vm.stack)-1] = value
+		case OP_GET_SUPER:
+			name := readString()
+			superclass := vm.pop().(*LoxClass)
+
+			method, ok := vm.bindMethod(superclass, name)
+			if !ok {
+				return nil, vm.runtimeError("Undefined property '%s'.", name)
+			}
+			vm.stack[len(vm.stack)-1] = method
+		case OP_EQUAL:
+			b := vm.pop()
+			vm.stack[len(vm.stack)-1] = isEqual(vm.peek(0), b)
+		case OP_NOT_EQUAL:
+			b := vm.pop()
+			vm.stack[len(vm.stack)-1] = !isEqual(vm.peek(0), b)
+		case OP_GREATER, OP_GREATER_EQUAL, OP_LESS, OP_LESS_EQUAL, OP_SUBTRACT, OP_MULTIPLY, OP_DIVIDE:
+			b, okB := vm.peek(0).(float64)
+			a, okA := vm.peek(1).(float64)
+			if !okA || !okB {
+				return nil, vm.runtimeError("Operands must be numbers.")
+			}
+
+			var result Value
+			switch op {
+			case OP_GREATER:
+				result = a > b
+			case OP_GREATER_EQUAL:
+				result = a >= b
+			case OP_LESS:
+				result = a < b
+			case OP_LESS_EQUAL:
+				result = a <= b
+			case OP_SUBTRACT:
+				result = a - b
+			case OP_MULTIPLY:
+				result = a * b
+			case OP_DIVIDE:
+				result = a / b
+			}
+
+			vm.pop()
+			vm.stack[len(vm.stack)-1] = result
+		case OP_ADD:
+			switch a := vm.peek(1).(type) {
+			case float64:
+				if b, ok := vm.peek(0).(float64); ok {
+					vm.pop()
+					vm.stack[len(vm.stack)-1] = a + b
+					continue
+				}
+			case string:
+				if b, ok := vm.peek(0).(string); ok {
+					vm.pop()
+					vm.stack[len(vm.stack)-1] = a + b
+					continue
+				}
+			}
+
+			return nil, vm.runtimeError("Operands must be two numbers or two strings.")
+		case OP_NOT:
+			vm.stack[len(vm.stack)-1] = !isTruthy(vm.peek(0))
+		case OP_NEGATE:
+			value, ok := vm.peek(0).(float64)
+			if !ok {
+				return nil, vm.runtimeError("Operand must be a number.")
+			}
+			vm.stack[len(vm.stack)-1] = -value
+		case OP_STRINGIFY:
+			vm.stack[len(vm.stack)-1] = stringify(vm.peek(0))
+		case OP_PRINT:
+			fmt.Fprintln(vm.stdout, stringify(vm.pop()))
+		case OP_JUMP:
+			offset := readShort()
+			frame.ip += offset
+		case OP_JUMP_IF_FALSE:
+			offset := readShort()
+			if !isTruthy(vm.peek(0)) {
+				frame.ip += offset
+			}
+		case OP_LOOP:
+			offset := readShort()
+			frame.ip -= offset
+		case OP_CALL:
+			argCount := int(readByte())
+			if err := vm.callValue(vm.peek(argCount), argCount); err != nil {
+				return nil, err
+			}
+			loadFrame()
+		case OP_CLOSURE:
+			function := constants[readShort()].(*CompiledFunction)
+			closure := newClosure(function)
+
+			for i := range closure.upvalues {
+				isLocal := readByte()
+				index := readShort()
+				if isLocal == 1 {
+					closure.upvalues[i] = vm.captureUpvalue(frame.slots + index)
+				} else {
+					closure.upvalues[i] = frame.closure.upvalues[index]
+				}
+			}
+
+			vm.push(closure)
+		case OP_CLOSE_UPVALUE:
+			vm.closeUpvalues(len(vm.stack) - 1)
+			vm.pop()
+		case OP_RETURN:
+			result := vm.pop()
+			vm.closeUpvalues(frame.slots)
+
+			slots := frame.slots
+			vm.frames = vm.frames[:len(vm.frames)-1]
+			vm.stack = vm.stack[:slots]
+
+			if len(vm.frames) == 0 {
+				return result, nil
+			}
+
+			vm.push(result)
+			loadFrame()
+		case OP_CLASS:
+			vm.push(newLoxClass(readString(), nil, make(map[string]*LoxFunction)))
+		case OP_INHERIT:
+			superclass, ok := vm.peek(1).(*LoxClass)
+			if !ok {
+				return nil, vm.runtimeError("Superclass must be a class.")
+			}
+
+			subclass := vm.peek(0).(*LoxClass)
+			subclass.superclass = superclass
+			for name, method := range superclass.closures {
+				subclass.closures[name] = method
+			}
+
+			vm.pop()
+		case OP_METHOD:
+			name := readString()
+			class := vm.peek(1).(*LoxClass)
+			class.closures[name] = vm.pop().(*Closure)
+		case OP_BUILD_LIST:
+			count := readShort()
+			elements := make([]Value, count)
+			copy(elements, vm.stack[len(vm.stack)-count:])
+
+			vm.stack = vm.stack[:len(vm.stack)-count]
+			vm.push(newLoxList(elements))
+		case OP_BUILD_MAP:
+			count := readShort()
+			entries := vm.stack[len(vm.stack)-2*count:]
+
+			result := newLoxMap()
+			for i := 0; i < len(entries); i += 2 {
+				if err := result.set(entries[i], entries[i+1]); err != nil {
+					return nil, vm.runtimeError("%s", err.Error())
+				}
+			}
+
+			vm.stack = vm.stack[:len(vm.stack)-2*count]
+			vm.push(result)
+		case OP_GET_INDEX:
+			list, ok := vm.peek(1).(*LoxList)
+			if !ok {
+				return nil, vm.runtimeError("Only lists can be indexed.")
+			}
+
+			value, err := list.getIndex(vm.pop())
+			if err != nil {
+				return nil, vm.runtimeError("%s", err.Error())
+			}
+			vm.stack[len(vm.stack)-1] = value
+		case OP_SET_INDEX:
+			list, ok := vm.peek(2).(*LoxList)
+			if !ok {
+				return nil, vm.runtimeError("Only lists can be indexed.")
+			}
+
+			value := vm.pop()
+			if err := list.setIndex(vm.pop(), value); err != nil {
+				return nil, vm.runtimeError("%s", err.Error())
+			}
+			vm.stack[len(vm.stack)-1] = value
+		case OP_SLICE:
+			list, ok := vm.peek(2).(*LoxList)
+			if !ok {
+				return nil, vm.runtimeError("Only lists can be sliced.")
+			}
+
+			end := vm.pop()
+			slice, err := list.slice(vm.pop(), end)
+			if err != nil {
+				return nil, vm.runtimeError("%s", err.Error())
+			}
+			vm.stack[len(vm.stack)-1] = slice
+		default:
+			return nil, vm.runtimeError("Unknown opcode %d.", op)
+		}
+	}
+}
@@ -0,0 +1,437 @@
+// Package lsp implements a Language Server Protocol server for Lox. It
+// speaks JSON-RPC over any reader and writer, so it can be driven over
+// stdio by an editor or by a scripted client.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/michalzarsm/lox-interpreter/internal/framing"
+	"github.com/michalzarsm/lox-interpreter/lox"
+)
+
+var keywords = []lox.TokenType{
+	lox.AND, lox.BREAK, lox.CLASS, lox.CONTINUE, lox.ELSE, lox.FALSE, lox.FUN, lox.FOR, lox.IF,
+	lox.NIL, lox.OR, lox.PRINT, lox.RETURN, lox.SUPER, lox.THIS, lox.TRUE, lox.VAR, lox.WHILE,
+}
+
+// document is an open file and what is known about its current text.
+type document struct {
+	uri        string
+	version    int
+	text       string
+	lineStarts []int
+	analysis   *lox.Analysis
+}
+
+func newDocument(uri string, version int, text string) *document {
+	lineStarts := []int{0}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+
+	return &document{
+		uri:        uri,
+		version:    version,
+		text:       text,
+		lineStarts: lineStarts,
+		analysis:   lox.Analyze(text),
+	}
+}
+
+// position converts a Lox position to an LSP one, which counts lines from
+// zero and characters in UTF-16 code units.
+func (d *document) position(position lox.Position) Position {
+	if position.Line < 1 || position.Line > len(d.lineStarts) {
+		return Position{}
+	}
+
+	lineStart := d.lineStarts[position.Line-1]
+	offset := min(max(position.Offset, lineStart), len(d.text))
+
+	return Position{Line: position.Line - 1, Character: utf16Length(d.text[lineStart:offset])}
+}
+
+func (d *document) rangeOf(span lox.Span) Range {
+	return Range{Start: d.position(span.Start), End: d.position(span.End)}
+}
+
+func (d *document) location(span lox.Span) Location {
+	return Location{URI: d.uri, Range: d.rangeOf(span)}
+}
+
+// offset converts an LSP position to a byte offset into the text.
+func (d *document) offset(position Position) int {
+	if position.Line < 0 {
+		return 0
+	}
+
+	if position.Line >= len(d.lineStarts) {
+		return len(d.text)
+	}
+
+	offset := d.lineStarts[position.Line]
+	for units := 0; units < position.Character && offset < len(d.text) && d.text[offset] != '\n'; {
+		r, size := utf8.DecodeRuneInString(d.text[offset:])
+		units += utf16RuneLength(r)
+		offset += size
+	}
+
+	return offset
+}
+
+func utf16Length(text string) int {
+	length := 0
+	for _, r := range text {
+		length += utf16RuneLength(r)
+	}
+
+	return length
+}
+
+// utf16RuneLength is the number of UTF-16 code units that encode r.
+func utf16RuneLength(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+
+	return 1
+}
+
+// Server answers LSP requests for the documents an editor has open.
+type Server struct {
+	reader    *bufio.Reader
+	writer    io.Writer
+	documents map[string]*document
+	shutdown  bool
+}
+
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{
+		reader:    bufio.NewReader(in),
+		writer:    out,
+		documents: make(map[string]*document),
+	}
+}
+
+// Run serves requests until the client sends exit or closes the input.
+func (s *Server) Run() error {
+	for {
+		body, err := framing.Read(s.reader)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			if err := s.reply(nil, nil, &responseError{Code: codeParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if req.Method == "exit" {
+			return nil
+		}
+
+		result, rpcErr := s.handle(req)
+		if req.ID == nil {
+			continue
+		}
+
+		if err := s.reply(req.ID, result, rpcErr); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) reply(id *json.RawMessage, result any, rpcErr *responseError) error {
+	if rpcErr != nil {
+		return framing.Write(s.writer, errorResponse{JSONRPC: "2.0", ID: id, Error: rpcErr})
+	}
+
+	return framing.Write(s.writer, response{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+func (s *Server) notify(method string, params any) error {
+	return framing.Write(s.writer, notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (s *Server) handle(req request) (any, *responseError) {
+	if s.shutdown {
+		return nil, &responseError{Code: codeInvalidRequest, Message: "server is shutting down"}
+	}
+
+	switch req.Method {
+	case "initialize":
+		return InitializeResult{
+			Capabilities: ServerCapabilities{
+				TextDocumentSync:       SyncFull,
+				DocumentSymbolProvider: true,
+				DefinitionProvider:     true,
+				ReferencesProvider:     true,
+				HoverProvider:          true,
+				CompletionProvider:     struct{}{},
+			},
+			ServerInfo: ServerInfo{Name: "lox"},
+		}, nil
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := decode(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.open(newDocument(params.TextDocument.URI, params.TextDocument.Version, params.TextDocument.Text))
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := decode(req.Params, &params); err != nil {
+			return nil, err
+		}
+		if len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+		text := params.ContentChanges[len(params.ContentChanges)-1].Text
+		return nil, s.open(newDocument(params.TextDocument.URI, params.TextDocument.Version, text))
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := decode(req.Params, &params); err != nil {
+			return nil, err
+		}
+		delete(s.documents, params.TextDocument.URI)
+		return nil, s.publish(PublishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []Diagnostic{}})
+	case "textDocument/documentSymbol":
+		var params DocumentSymbolParams
+		if err := decode(req.Params, &params); err != nil {
+			return nil, err
+		}
+		doc, err := s.document(params.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		return documentSymbols(doc), nil
+	case "textDocument/definition":
+		var params TextDocumentPositionParams
+		if err := decode(req.Params, &params); err != nil {
+			return nil, err
+		}
+		doc, err := s.document(params.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		symbol, _, ok := doc.analysis.SymbolAt(doc.offset(params.Position))
+		if !ok {
+			return nil, nil
+		}
+		return doc.location(symbol.Name.Span), nil
+	case "textDocument/references":
+		var params ReferenceParams
+		if err := decode(req.Params, &params); err != nil {
+			return nil, err
+		}
+		doc, err := s.document(params.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		return references(doc, params), nil
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err := decode(req.Params, &params); err != nil {
+			return nil, err
+		}
+		doc, err := s.document(params.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		symbol, token, ok := doc.analysis.SymbolAt(doc.offset(params.Position))
+		if !ok {
+			return nil, nil
+		}
+		return Hover{
+			Contents: MarkupContent{Kind: "markdown", Value: "```lox\n" + symbol.Signature() + "\n```"},
+			Range:    doc.rangeOf(token.Span),
+		}, nil
+	case "textDocument/completion":
+		var params TextDocumentPositionParams
+		if err := decode(req.Params, &params); err != nil {
+			return nil, err
+		}
+		doc, err := s.document(params.TextDocument.URI)
+		if err != nil {
+			return nil, err
+		}
+		return completions(doc, doc.offset(params.Position)), nil
+	}
+
+	if strings.HasPrefix(req.Method, "$/") {
+		return nil, nil
+	}
+
+	return nil, &responseError{Code: codeMethodNotFound, Message: "method not found: " + req.Method}
+}
+
+func decode(params json.RawMessage, value any) *responseError {
+	if err := json.Unmarshal(params, value); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	return nil
+}
+
+func (s *Server) document(uri string) (*document, *responseError) {
+	doc, ok := s.documents[uri]
+	if !ok {
+		return nil, &responseError{Code: codeInvalidParams, Message: "document is not open: " + uri}
+	}
+
+	return doc, nil
+}
+
+// open stores the document and publishes its diagnostics.
+func (s *Server) open(doc *document) *responseError {
+	s.documents[doc.uri] = doc
+
+	diagnostics := make([]Diagnostic, 0, len(doc.analysis.Errors))
+	for _, err := range doc.analysis.Errors {
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    doc.rangeOf(err.Token.Span),
+			Severity: SeverityError,
+			Code:     err.Type,
+			Source:   "lox",
+			Message:  err.Message,
+		})
+	}
+
+	return s.publish(PublishDiagnosticsParams{URI: doc.uri, Version: doc.version, Diagnostics: diagnostics})
+}
+
+func (s *Server) publish(params PublishDiagnosticsParams) *responseError {
+	if err := s.notify("textDocument/publishDiagnostics", params); err != nil {
+		return &responseError{Code: codeInvalidRequest, Message: err.Error()}
+	}
+
+	return nil
+}
+
+// documentSymbols lists the variables, functions, classes and methods of
+// the document, nesting each under the function or class it is declared
+// in. Parameters are left out.
+func documentSymbols(doc *document) []DocumentSymbol {
+	children := make(map[*lox.Symbol][]*lox.Symbol)
+	roots := make([]*lox.Symbol, 0)
+
+	for _, symbol := range doc.analysis.Symbols {
+		if symbol.Kind == lox.SymbolParameter {
+			continue
+		}
+
+		if symbol.Parent == nil {
+			roots = append(roots, symbol)
+		} else {
+			children[symbol.Parent] = append(children[symbol.Parent], symbol)
+		}
+	}
+
+	var convert func(symbols []*lox.Symbol) []DocumentSymbol
+	convert = func(symbols []*lox.Symbol) []DocumentSymbol {
+		result := make([]DocumentSymbol, 0, len(symbols))
+		for _, symbol := range symbols {
+			result = append(result, DocumentSymbol{
+				Name:           symbol.Name.Lexeme,
+				Detail:         symbol.Signature(),
+				Kind:           symbolKind(symbol.Kind),
+				Range:          doc.rangeOf(symbol.Declaration.Span()),
+				SelectionRange: doc.rangeOf(symbol.Name.Span),
+				Children:       convert(children[symbol]),
+			})
+		}
+
+		return result
+	}
+
+	return convert(roots)
+}
+
+func symbolKind(kind lox.SymbolKind) int {
+	switch kind {
+	case lox.SymbolClass:
+		return SymbolKindClass
+	case lox.SymbolMethod:
+		return SymbolKindMethod
+	case lox.SymbolFunction:
+		return SymbolKindFunction
+	}
+
+	return SymbolKindVariable
+}
+
+func references(doc *document, params ReferenceParams) []Location {
+	locations := make([]Location, 0)
+
+	symbol, _, ok := doc.analysis.SymbolAt(doc.offset(params.Position))
+	if !ok {
+		return locations
+	}
+
+	if params.Context.IncludeDeclaration {
+		locations = append(locations, doc.location(symbol.Name.Span))
+	}
+
+	for _, reference := range symbol.References {
+		locations = append(locations, doc.location(reference.Span))
+	}
+
+	return locations
+}
+
+// completions offers every keyword and every name visible at offset. The
+// client filters them against what has been typed.
+func completions(doc *document, offset int) []CompletionItem {
+	items := make([]CompletionItem, 0, len(keywords))
+
+	for _, keyword := range keywords {
+		items = append(items, CompletionItem{Label: string(keyword), Kind: CompletionKindKeyword})
+	}
+
+	// Later declarations shadow earlier ones, so they are seen first.
+	visible := doc.analysis.VisibleAt(offset)
+	seen := make(map[string]bool)
+	for i := len(visible) - 1; i >= 0; i-- {
+		symbol := visible[i]
+		if seen[symbol.Name.Lexeme] {
+			continue
+		}
+		seen[symbol.Name.Lexeme] = true
+
+		kind := CompletionKindVariable
+		switch symbol.Kind {
+		case lox.SymbolFunction:
+			kind = CompletionKindFunction
+		case lox.SymbolClass:
+			kind = CompletionKindClass
+		}
+
+		items = append(items, CompletionItem{Label: symbol.Name.Lexeme, Kind: kind, Detail: symbol.Signature()})
+	}
+
+	names := items[len(keywords):]
+	sort.Slice(names, func(i, j int) bool {
+		return names[i].Label < names[j].Label
+	})
+
+	return items
+}
@@ -0,0 +1,337 @@
+package lsp_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/michalzarsm/lox-interpreter/internal/framing"
+	"github.com/michalzarsm/lox-interpreter/lsp"
+)
+
+const (
+	goodURI = "file:///greeter.lox"
+	badURI  = "file:///broken.lox"
+)
+
+const goodSource = `class Greeter {
+  greet(name) {
+    return "Hello, " + name;
+  }
+}
+var greeter = Greeter();
+fun shout(text) {
+  return text + "!";
+}
+print shout(greeter.greet("lox"));
+`
+
+// client drives a Server over pipes the way an editor would, one message
+// at a time.
+type client struct {
+	t      *testing.T
+	reader *bufio.Reader
+	writer io.WriteCloser
+	nextID int
+	done   chan error
+
+	// notifications holds the notifications read while waiting for a
+	// response, oldest first.
+	notifications []message
+}
+
+type message struct {
+	ID     *int             `json:"id"`
+	Method string           `json:"method"`
+	Params json.RawMessage  `json:"params"`
+	Result json.RawMessage  `json:"result"`
+	Error  *json.RawMessage `json:"error"`
+}
+
+func newClient(t *testing.T) *client {
+	t.Helper()
+
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+
+	c := &client{
+		t:      t,
+		reader: bufio.NewReader(clientIn),
+		writer: clientOut,
+		done:   make(chan error, 1),
+	}
+
+	go func() {
+		c.done <- lsp.NewServer(serverIn, serverOut).Run()
+		serverOut.Close()
+	}()
+
+	t.Cleanup(func() {
+		c.send(map[string]any{"jsonrpc": "2.0", "method": "exit"})
+		if err := <-c.done; err != nil {
+			t.Errorf("server stopped with %v", err)
+		}
+		clientOut.Close()
+	})
+
+	return c
+}
+
+func (c *client) send(value any) {
+	c.t.Helper()
+
+	if err := framing.Write(c.writer, value); err != nil {
+		c.t.Fatalf("write: %v", err)
+	}
+}
+
+func (c *client) read() message {
+	c.t.Helper()
+
+	body, err := framing.Read(c.reader)
+	if err != nil {
+		c.t.Fatalf("read: %v", err)
+	}
+
+	var msg message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		c.t.Fatalf("decode %s: %v", body, err)
+	}
+
+	return msg
+}
+
+// call sends a request and decodes the result of its response into
+// result, keeping any notification that arrives first.
+func (c *client) call(method string, params any, result any) {
+	c.t.Helper()
+
+	c.nextID += 1
+	id := c.nextID
+	c.send(map[string]any{"jsonrpc": "2.0", "id": id, "method": method, "params": params})
+
+	for {
+		msg := c.read()
+		if msg.ID == nil {
+			c.notifications = append(c.notifications, msg)
+			continue
+		}
+
+		if *msg.ID != id {
+			c.t.Fatalf("%s: got the response to request %d, want %d", method, *msg.ID, id)
+		}
+		if msg.Error != nil {
+			c.t.Fatalf("%s: %s", method, *msg.Error)
+		}
+		if err := json.Unmarshal(msg.Result, result); err != nil {
+			c.t.Fatalf("%s: decode %s: %v", method, msg.Result, err)
+		}
+		return
+	}
+}
+
+func (c *client) notify(method string, params any) {
+	c.t.Helper()
+
+	c.send(map[string]any{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+// diagnostics waits for the diagnostics published for uri.
+func (c *client) diagnostics(uri string) lsp.PublishDiagnosticsParams {
+	c.t.Helper()
+
+	for {
+		var msg message
+		if len(c.notifications) > 0 {
+			msg, c.notifications = c.notifications[0], c.notifications[1:]
+		} else {
+			msg = c.read()
+		}
+
+		if msg.Method != "textDocument/publishDiagnostics" {
+			c.t.Fatalf("got %q while waiting for diagnostics", msg.Method)
+		}
+
+		var params lsp.PublishDiagnosticsParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			c.t.Fatalf("decode diagnostics: %v", err)
+		}
+		if params.URI == uri {
+			return params
+		}
+	}
+}
+
+func (c *client) open(uri string, text string) lsp.PublishDiagnosticsParams {
+	c.t.Helper()
+
+	c.notify("textDocument/didOpen", lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{URI: uri, LanguageID: "lox", Version: 1, Text: text},
+	})
+
+	return c.diagnostics(uri)
+}
+
+func at(uri string, line int, character int) lsp.TextDocumentPositionParams {
+	return lsp.TextDocumentPositionParams{
+		TextDocument: lsp.TextDocumentIdentifier{URI: uri},
+		Position:     lsp.Position{Line: line, Character: character},
+	}
+}
+
+func span(line int, start int, end int) lsp.Range {
+	return lsp.Range{Start: lsp.Position{Line: line, Character: start}, End: lsp.Position{Line: line, Character: end}}
+}
+
+func TestServer(t *testing.T) {
+	c := newClient(t)
+
+	var initialized lsp.InitializeResult
+	c.call("initialize", map[string]any{"processId": nil, "rootUri": nil, "capabilities": map[string]any{}}, &initialized)
+	if initialized.ServerInfo.Name != "lox" {
+		t.Errorf("server name is %q, want \"lox\"", initialized.ServerInfo.Name)
+	}
+	capabilities := initialized.Capabilities
+	if capabilities.TextDocumentSync != lsp.SyncFull || !capabilities.DocumentSymbolProvider || !capabilities.DefinitionProvider ||
+		!capabilities.ReferencesProvider || !capabilities.HoverProvider || capabilities.CompletionProvider == nil {
+		t.Errorf("capabilities are %+v", capabilities)
+	}
+	c.notify("initialized", struct{}{})
+
+	t.Run("publishDiagnostics", func(t *testing.T) {
+		c.t = t
+
+		if published := c.open(goodURI, goodSource); len(published.Diagnostics) != 0 {
+			t.Errorf("a valid document has diagnostics %+v", published.Diagnostics)
+		}
+
+		published := c.open(badURI, "var a = 1;\nprint a +;\n")
+		if published.Version != 1 {
+			t.Errorf("diagnostics are for version %d, want 1", published.Version)
+		}
+
+		want := []lsp.Diagnostic{{
+			Range:    span(1, 9, 10),
+			Severity: lsp.SeverityError,
+			Code:     "SyntaxError",
+			Source:   "lox",
+			Message:  "Expect expression.",
+		}}
+		if !reflect.DeepEqual(published.Diagnostics, want) {
+			t.Errorf("diagnostics are %+v, want %+v", published.Diagnostics, want)
+		}
+	})
+
+	t.Run("documentSymbol", func(t *testing.T) {
+		c.t = t
+
+		var symbols []lsp.DocumentSymbol
+		c.call("textDocument/documentSymbol", lsp.DocumentSymbolParams{TextDocument: lsp.TextDocumentIdentifier{URI: goodURI}}, &symbols)
+
+		type outline struct {
+			name     string
+			kind     int
+			children []outline
+		}
+		var summarize func([]lsp.DocumentSymbol) []outline
+		summarize = func(symbols []lsp.DocumentSymbol) []outline {
+			var result []outline
+			for _, symbol := range symbols {
+				result = append(result, outline{symbol.Name, symbol.Kind, summarize(symbol.Children)})
+			}
+			return result
+		}
+
+		want := []outline{
+			{"Greeter", lsp.SymbolKindClass, []outline{{"greet", lsp.SymbolKindMethod, nil}}},
+			{"greeter", lsp.SymbolKindVariable, nil},
+			{"shout", lsp.SymbolKindFunction, nil},
+		}
+		if got := summarize(symbols); !reflect.DeepEqual(got, want) {
+			t.Errorf("symbols are %+v, want %+v", got, want)
+		}
+
+		if len(symbols) == 3 && symbols[2].SelectionRange != span(6, 4, 9) {
+			t.Errorf("shout is selected at %+v, want %+v", symbols[2].SelectionRange, span(6, 4, 9))
+		}
+	})
+
+	t.Run("definition", func(t *testing.T) {
+		c.t = t
+
+		var location lsp.Location
+		c.call("textDocument/definition", at(goodURI, 9, 14), &location)
+
+		want := lsp.Location{URI: goodURI, Range: span(5, 4, 11)}
+		if location != want {
+			t.Errorf("definition is %+v, want %+v", location, want)
+		}
+	})
+
+	t.Run("references", func(t *testing.T) {
+		c.t = t
+
+		var locations []lsp.Location
+		c.call("textDocument/references", lsp.ReferenceParams{
+			TextDocumentPositionParams: at(goodURI, 5, 6),
+			Context:                    lsp.ReferenceContext{IncludeDeclaration: true},
+		}, &locations)
+
+		want := []lsp.Location{
+			{URI: goodURI, Range: span(5, 4, 11)},
+			{URI: goodURI, Range: span(9, 12, 19)},
+		}
+		if !reflect.DeepEqual(locations, want) {
+			t.Errorf("references are %+v, want %+v", locations, want)
+		}
+	})
+
+	t.Run("hover", func(t *testing.T) {
+		c.t = t
+
+		var hover lsp.Hover
+		c.call("textDocument/hover", at(goodURI, 9, 7), &hover)
+
+		want := lsp.Hover{
+			Contents: lsp.MarkupContent{Kind: "markdown", Value: "```lox\nfun shout(text)\n```"},
+			Range:    span(9, 6, 11),
+		}
+		if hover != want {
+			t.Errorf("hover is %+v, want %+v", hover, want)
+		}
+	})
+
+	t.Run("completion", func(t *testing.T) {
+		c.t = t
+
+		var items []lsp.CompletionItem
+		c.call("textDocument/completion", at(goodURI, 7, 9), &items)
+
+		labels := make(map[string]int)
+		for _, item := range items {
+			labels[item.Label] = item.Kind
+		}
+
+		want := map[string]int{
+			"while":   lsp.CompletionKindKeyword,
+			"Greeter": lsp.CompletionKindClass,
+			"greeter": lsp.CompletionKindVariable,
+			"shout":   lsp.CompletionKindFunction,
+			"text":    lsp.CompletionKindVariable,
+		}
+		for label, kind := range want {
+			if got, ok := labels[label]; !ok || got != kind {
+				t.Errorf("completion %q has kind %d (offered: %v), want %d", label, got, ok, kind)
+			}
+		}
+		if _, ok := labels["name"]; ok {
+			t.Error("completion offers the parameter of another function")
+		}
+	})
+
+	c.t = t
+	var result any
+	c.call("shutdown", nil, &result)
+}
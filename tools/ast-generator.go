@@ -2,7 +2,9 @@ package main
 
 import (
 	"fmt"
+	"go/token"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -18,17 +20,37 @@ func main() {
 		"Assign       : Name Token, value Expr",
 		"Ternary      : Condition Expr, TrueExpr Expr, FalseExpr Expr",
 		"Binary       : Left Expr, Operator Token, Right Expr",
+		"Call         : Callee Expr, Paren Token, Arguments []Expr",
+		"Get          : Object Expr, Name Token",
 		"Grouping     : Expression Expr",
+		"Index        : Object Expr, Bracket Token, Index Expr",
+		"List         : Bracket Token, Elements []Expr",
 		"Literal      : Value any",
+		"Logical      : Left Expr, Operator Token, Right Expr",
+		"Map          : Brace Token, Keys []Expr, Values []Expr",
+		"Set          : Object Expr, Name Token, Value Expr",
+		"SetIndex     : Object Expr, Bracket Token, Index Expr, Value Expr",
+		"Slice        : Object Expr, Bracket Token, Start Expr, End Expr",
+		"ToString     : Expression Expr",
+		"Super        : Keyword Token, Method Token",
+		"This         : Keyword Token",
 		"Unary        : Operator Token, Right Expr",
 		"VariableExpr : Name Token",
 	})
 
 	defineAst(outputDir, "Stmt", []string{
 		"Block        : Statements []Stmt",
+		"Break        : Keyword Token, Label Token",
+		"Class        : Name Token, Superclass Expr, Methods []*Function",
+		"Continue     : Keyword Token, Label Token",
 		"Expression   : Expression Expr",
+		"For          : Initializer Stmt, Condition Expr, Increment Expr, Body Stmt, Label Token",
+		"Function     : Name Token, Params []Token, Body []Stmt",
+		"If           : Condition Expr, ThenBranch Stmt, ElseBranch Stmt",
 		"Print        : Expression Expr",
+		"Return       : Keyword Token, Value Expr",
 		"VariableStmt : Name Token, Initializer Expr",
+		"While        : Condition Expr, Body Stmt, Label Token",
 	})
 }
 
@@ -42,9 +64,10 @@ func defineAst(outputDir string, baseName string, types []string) {
 		os.Exit(1)
 	}
 
-	file.WriteString("package main\n\n")
+	file.WriteString("package " + filepath.Base(outputDir) + "\n\n")
 	file.WriteString("type" + " " + baseName + " " + "interface {\n")
 	file.WriteString("	Accept(visitor" + " " + baseName + "Visitor) any\n")
+	file.WriteString("	Span() Span\n")
 	file.WriteString("}\n\n")
 
 	defineVisitor(file, baseName, types)
@@ -55,6 +78,7 @@ func defineAst(outputDir string, baseName string, types []string) {
 		fields := strings.Trim(splitType[1], " ")
 		defineType(file, structName, fields)
 		defineAccept(file, baseName, structName)
+		defineSpan(file, structName)
 	}
 }
 
@@ -67,20 +91,32 @@ func defineType(file *os.File, structName string, fieldList string) {
 		fieldType := splitField[1]
 		file.WriteString("	" + fieldName + " " + fieldType + "\n")
 	}
+	file.WriteString("	span Span\n")
 	file.WriteString("}\n\n")
 }
 
 func defineAccept(file *os.File, baseName, structName string) {
-	file.WriteString("func (this" + structName + " " + structName + ") " + "Accept(visitor" + " " + baseName + "Visitor) any {\n")
+	file.WriteString("func (this" + structName + " *" + structName + ") " + "Accept(visitor" + " " + baseName + "Visitor) any {\n")
 	file.WriteString("	return visitor.Visit" + structName + baseName + "(this" + structName + ")\n")
 	file.WriteString("}\n\n")
 }
 
+// defineSpan gives every node the source range it was parsed from.
+func defineSpan(file *os.File, structName string) {
+	file.WriteString("func (this" + structName + " *" + structName + ") Span() Span {\n")
+	file.WriteString("	return this" + structName + ".span\n")
+	file.WriteString("}\n\n")
+}
+
 func defineVisitor(file *os.File, baseName string, types []string) {
 	fmt.Fprintf(file, "type"+" "+baseName+"Visitor interface {\n")
 	for _, t := range types {
 		className := strings.Trim(strings.Split(t, ":")[0], " ")
-		fmt.Fprintf(file, "\tVisit%s%s(%s %s) any\n", className, baseName, strings.ToLower(className), className)
+		paramName := strings.ToLower(className)
+		if token.IsKeyword(paramName) {
+			paramName += baseName
+		}
+		fmt.Fprintf(file, "\tVisit%s%s(%s *%s) any\n", className, baseName, paramName, className)
 	}
 	fmt.Fprintf(file, "}\n\n")
 }